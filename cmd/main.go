@@ -8,11 +8,15 @@ import (
 	"storynest/internal/config"
 	"storynest/internal/story/nest"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+// shutdownGrace is how long to wait for commands to stop cleanly after Ctrl+C
+const shutdownGrace = 3 * time.Second
+
 func main() {
 
 	config.SetDefaults()
@@ -25,9 +29,15 @@ func main() {
 
 	go func() {
 		<-sigChan
+		// Cancelling stops speech, synthesis and subprocesses through their contexts
 		app.Cancel()
-		app.Tts.Stop()
 		fmt.Println("\n" + colours.Warning.Sprint("👋 Goodbye! Sweet dreams! 🌙"))
+
+		// Give playback a moment to wind down; a second Ctrl+C exits straight away
+		select {
+		case <-sigChan:
+		case <-time.After(shutdownGrace):
+		}
 		os.Exit(0)
 	}()
 
@@ -67,9 +77,9 @@ from public libraries around the world. Perfect for bedtime! 🌙
 
 	// Read command
 	readCmd := &cobra.Command{
-		Use:   "read [story-id]",
+		Use:   "read [story-id...]",
 		Short: "📖 Read a specific story",
-		Long:  "Read a story by its ID or select from a list",
+		Long:  "Read a story by its ID or select from a list. Give several IDs to queue them up",
 		Run:   app.ReadStory,
 	}
 
@@ -81,29 +91,36 @@ from public libraries around the world. Perfect for bedtime! 🌙
 		Run:   app.ManageLibraries,
 	}
 
-	// Settings command
-	settingsCmd := &cobra.Command{
-		Use:   "settings",
-		Short: "⚙️ Configure TTS settings",
-		Long:  "Adjust voice, speed, and volume settings",
-		Run:   app.ConfigureSettings,
-	}
-
 	// Add flags
 	listCmd.Flags().StringP("genre", "g", "", "Filter by genre")
 	listCmd.Flags().StringP("age", "a", "", "Filter by age group")
 
 	readCmd.Flags().BoolP("interactive", "i", false, "Interactive story selection")
+	readCmd.Flags().Duration("pause", 3*time.Second, "Pause between queued stories")
+	readCmd.Flags().Duration("sleep", 0, "Fade out and stop after this long (e.g. 20m)")
+	randomCmd.Flags().Duration("sleep", 0, "Fade out and stop after this long (e.g. 20m)")
+	readCmd.Flags().String("ambient", "", "Background sound: an .mp3/.wav file or white, pink, brown noise")
+	randomCmd.Flags().String("ambient", "", "Background sound: an .mp3/.wav file or white, pink, brown noise")
+	readCmd.Flags().BoolP("follow", "f", false, "Show the story text and highlight the words as they are read")
 
 	rootCmd.PersistentFlags().StringP("voice", "v", "", "Optional voice to use for reading")
 
 	rootCmd.Flags().SetInterspersed(true)
 
-	rootCmd.AddCommand(listCmd, randomCmd, readCmd, librariesCmd, settingsCmd)
+	rootCmd.AddCommand(listCmd, randomCmd, readCmd, librariesCmd)
+	app.AddSettingsCommands(rootCmd)
+	app.AddTTSCommands(rootCmd)
+	app.AddCacheCommands(rootCmd)
+	app.AddPrefetchCommands(rootCmd)
 
 	// Add Gutenberg commands
 	app.AddGutenbergCommands(rootCmd)
 
+	// Add playlist commands
+	app.AddPlaylistCommands(rootCmd)
+	app.AddExportCommands(rootCmd)
+	app.AddUICommands(rootCmd)
+
 	// Load sample data including Gutenberg
 	app.LoadSampleLibrariesWithGutenberg()
 
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/viper"
 )
@@ -13,18 +16,185 @@ func SetDefaults() {
 	viper.SetDefault("tts.speed", 1.0)
 	viper.SetDefault("tts.volume", 0.8)
 
-	// Try to use Chirp if Google credentials are available, otherwise auto-select
+	// Try to use Google's voices if credentials are available, otherwise auto-select
 	if hasGoogleCredentials() {
-		viper.SetDefault("tts.type", "chirp")
+		viper.SetDefault("tts.type", "googleclassic")
 		viper.SetDefault("tts.voice", "en-US-Journey-F") // Child-friendly default
 	} else {
 		viper.SetDefault("tts.type", "auto") // Auto-select best engine
 		viper.SetDefault("tts.voice", "default")
 	}
 
+	viper.SetDefault("tts.engines", []string{}) // Fallback order; every engine set up here when empty
+
 	viper.SetDefault("tts.cache_enabled", true)
-	viper.SetDefault("tts.cache_path", "C:\\Users\\tahcoh\\AppData\\Local\\storynest")
+	viper.SetDefault("tts.cache_path", "")         // The user's cache directory when empty, see CacheDir
 	viper.SetDefault("tts.cache_max_size_mb", 500) // 500MB cache limit
+
+	viper.SetDefault("tts.piper.path", "")                                  // Found on the PATH when empty
+	viper.SetDefault("tts.piper.models_dir", filepath.Join(Dir(), "piper")) // .onnx voices with their .onnx.json
+
+	viper.SetDefault("tts.command.output", "stdout") // Or file, written to {out}
+	viper.SetDefault("tts.command.format", "wav")    // Or mp3, or raw 16-bit mono PCM with tts.command.sample_rate
+	viper.SetDefault("tts.command.rate_base", 175)   // {rate} at normal speed
+
+	viper.SetDefault("tts.http.base_url", "") // e.g. http://localhost:8880/v1
+	viper.SetDefault("tts.http.model", "tts-1")
+	viper.SetDefault("tts.http.voice", "alloy")
+	viper.SetDefault("tts.http.format", "mp3") // Or wav, or pcm (16-bit mono at tts.http.sample_rate)
+	viper.SetDefault("tts.http.sample_rate", 24000)
+	viper.SetDefault("tts.http.retries", 3)
+	viper.SetDefault("tts.http.timeout", "60s")
+
+	viper.SetDefault("playback.pause_between", "3s") // Quiet gap between queued stories
+	viper.SetDefault("playback.sleep_timer", "20m")  // Used when the sleep timer is started without a time
+
+	viper.SetDefault("ambient.source", "")    // Background sound file, or white/pink/brown noise
+	viper.SetDefault("ambient.volume", 0.3)   // Relative to the narration
+	viper.SetDefault("ambient.duck", 0.5)     // Fraction of the ambient volume kept while speech plays
+	viper.SetDefault("ambient.linger", "10m") // Keep playing this long after the stories end
+}
+
+// Dir returns the directory where StoryNest keeps its config and user data
+func Dir() string {
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(homeDir, ".storynest")
+	}
+	return ".storynest"
+}
+
+// CacheDir is where synthesized audio is kept: tts.cache_path if it is set, otherwise
+// storynest in the user's cache directory, or under Dir if there isn't one
+func CacheDir() string {
+	if path := viper.GetString("tts.cache_path"); path != "" {
+		return path
+	}
+	if cacheDir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(cacheDir, "storynest")
+	}
+	return filepath.Join(Dir(), "cache")
+}
+
+// Path is the config file that settings are saved to
+func Path() string {
+	return filepath.Join(Dir(), "storynest.yaml")
+}
+
+// Save writes a single setting to the config file, leaving everything else in it alone,
+// and applies it to the running app
+func Save(key string, value interface{}) error {
+	settings, err := readFile()
+	if err != nil {
+		return err
+	}
+
+	setNested(settings, strings.Split(key, "."), value)
+	if err := writeFile(settings); err != nil {
+		return err
+	}
+
+	viper.Set(key, value)
+	return nil
+}
+
+// Reset removes settings from the config file so their defaults apply again
+func Reset(keys ...string) error {
+	settings, err := readFile()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		deleteNested(settings, strings.Split(key, "."))
+	}
+	if err := writeFile(settings); err != nil {
+		return err
+	}
+
+	// Drop anything set earlier in this run and pick up the file without the old values
+	for _, key := range keys {
+		viper.Set(key, nil)
+	}
+	_ = viper.ReadInConfig()
+	return nil
+}
+
+// IsSet reports whether the config file sets key
+func IsSet(key string) bool {
+	settings, err := readFile()
+	if err != nil {
+		return false
+	}
+
+	var node interface{} = settings
+	for _, part := range strings.Split(key, ".") {
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if node, ok = m[part]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// readFile loads just what is in the config file, without defaults or flags
+func readFile() (map[string]interface{}, error) {
+	v := viper.New()
+	v.SetConfigFile(Path())
+	if err := v.ReadInConfig(); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string]interface{}{}, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return v.AllSettings(), nil
+}
+
+// writeFile replaces the config file with settings, atomically so a crash can't leave it half written
+func writeFile(settings map[string]interface{}) error {
+	if err := os.MkdirAll(Dir(), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	v := viper.New()
+	if err := v.MergeConfigMap(settings); err != nil {
+		return fmt.Errorf("failed to prepare config: %w", err)
+	}
+
+	tmp := filepath.Join(Dir(), ".storynest.tmp.yaml")
+	if err := v.WriteConfigAs(tmp); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Rename(tmp, Path()); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to save config file: %w", err)
+	}
+	return nil
+}
+
+func setNested(m map[string]interface{}, path []string, value interface{}) {
+	for _, part := range path[:len(path)-1] {
+		child, ok := m[part].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			m[part] = child
+		}
+		m = child
+	}
+	m[path[len(path)-1]] = value
+}
+
+func deleteNested(m map[string]interface{}, path []string) {
+	for _, part := range path[:len(path)-1] {
+		child, ok := m[part].(map[string]interface{})
+		if !ok {
+			return
+		}
+		m = child
+	}
+	delete(m, path[len(path)-1])
 }
 
 func hasGoogleCredentials() bool {
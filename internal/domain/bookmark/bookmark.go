@@ -0,0 +1,83 @@
+package bookmark
+
+import (
+	"fmt"
+	"path/filepath"
+	"storynest/internal/domain/jsonfile"
+	"time"
+)
+
+// Bookmark remembers where reading of a story stopped
+type Bookmark struct {
+	StoryID string    `json:"story_id"`
+	Offset  int       `json:"offset"` // byte offset into the story content
+	SavedAt time.Time `json:"saved_at"`
+}
+
+// Store persists bookmarks as a JSON file
+type Store struct {
+	file string
+}
+
+// NewStore creates a bookmark store backed by bookmarks.json in dir
+func NewStore(dir string) *Store {
+	return &Store{file: filepath.Join(dir, "bookmarks.json")}
+}
+
+// load reads every bookmark from disk, returning an empty set if none are saved yet
+func (s *Store) load() (map[string]Bookmark, error) {
+	bookmarks := make(map[string]Bookmark)
+	if err := jsonfile.Load(s.file, &bookmarks); err != nil {
+		return nil, fmt.Errorf("failed to read bookmarks: %w", err)
+	}
+	return bookmarks, nil
+}
+
+// save writes every bookmark to disk
+func (s *Store) save(bookmarks map[string]Bookmark) error {
+	if err := jsonfile.Save(s.file, bookmarks); err != nil {
+		return fmt.Errorf("failed to write bookmarks: %w", err)
+	}
+	return nil
+}
+
+// Get returns the bookmark for a story, if there is one
+func (s *Store) Get(storyID string) (*Bookmark, error) {
+	bookmarks, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	b, ok := bookmarks[storyID]
+	if !ok {
+		return nil, nil
+	}
+
+	return &b, nil
+}
+
+// Set saves the position reached in a story
+func (s *Store) Set(storyID string, offset int) error {
+	bookmarks, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	bookmarks[storyID] = Bookmark{StoryID: storyID, Offset: offset, SavedAt: time.Now()}
+	return s.save(bookmarks)
+}
+
+// Clear forgets the bookmark for a story, e.g. once it has been read to the end
+func (s *Store) Clear(storyID string) error {
+	bookmarks, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := bookmarks[storyID]; !ok {
+		return nil
+	}
+
+	delete(bookmarks, storyID)
+	return s.save(bookmarks)
+}
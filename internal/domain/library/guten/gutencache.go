@@ -287,6 +287,7 @@ func (gc *GutenCache) convertBooksToStories(books []GutendexBook) []story.Item {
 			Genre:       genre,
 			Duration:    gc.estimateDuration(book),
 			Description: gc.createDescription(book),
+			Cover:       book.Formats["image/jpeg"],
 		}
 
 		stories = append(stories, story)
@@ -0,0 +1,186 @@
+package playlist
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"storynest/internal/domain/jsonfile"
+	"time"
+)
+
+// ItemStatus records how far a playlist entry got the last time it was played
+type ItemStatus string
+
+const (
+	StatusPending  ItemStatus = "pending"
+	StatusPlaying  ItemStatus = "playing"
+	StatusFinished ItemStatus = "finished"
+	StatusSkipped  ItemStatus = "skipped"
+)
+
+// Item is a single story in a playlist along with its playback progress
+type Item struct {
+	StoryID    string     `json:"story_id"`
+	Status     ItemStatus `json:"status"`
+	LastPlayed *time.Time `json:"last_played,omitempty"`
+}
+
+// Playlist is a named, ordered list of stories played one after another
+type Playlist struct {
+	Name      string    `json:"name"`
+	Items     []Item    `json:"items"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Add appends stories to the end of the playlist
+func (p *Playlist) Add(storyIDs ...string) {
+	for _, id := range storyIDs {
+		p.Items = append(p.Items, Item{StoryID: id, Status: StatusPending})
+	}
+	p.UpdatedAt = time.Now()
+}
+
+// StoryIDs returns the story IDs in play order
+func (p *Playlist) StoryIDs() []string {
+	ids := make([]string, 0, len(p.Items))
+	for _, item := range p.Items {
+		ids = append(ids, item.StoryID)
+	}
+	return ids
+}
+
+// NextUnfinished returns the index of the first item that hasn't been played to the end,
+// or -1 if every item is finished
+func (p *Playlist) NextUnfinished() int {
+	for i, item := range p.Items {
+		if item.Status != StatusFinished {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetStatus updates the progress of the item at index
+func (p *Playlist) SetStatus(index int, status ItemStatus) {
+	if index < 0 || index >= len(p.Items) {
+		return
+	}
+	now := time.Now()
+	p.Items[index].Status = status
+	if status != StatusPending {
+		p.Items[index].LastPlayed = &now
+	}
+	p.UpdatedAt = now
+}
+
+// Reset marks every item as pending so the playlist plays from the start
+func (p *Playlist) Reset() {
+	for i := range p.Items {
+		p.Items[i].Status = StatusPending
+	}
+	p.UpdatedAt = time.Now()
+}
+
+// Store persists playlists as a JSON file
+type Store struct {
+	file string
+}
+
+// NewStore creates a playlist store backed by playlists.json in dir
+func NewStore(dir string) *Store {
+	return &Store{file: filepath.Join(dir, "playlists.json")}
+}
+
+// load reads every playlist from disk, returning an empty set if none are saved yet
+func (s *Store) load() (map[string]*Playlist, error) {
+	playlists := make(map[string]*Playlist)
+	if err := jsonfile.Load(s.file, &playlists); err != nil {
+		return nil, fmt.Errorf("failed to read playlists: %w", err)
+	}
+	return playlists, nil
+}
+
+// save writes every playlist to disk
+func (s *Store) save(playlists map[string]*Playlist) error {
+	if err := jsonfile.Save(s.file, playlists); err != nil {
+		return fmt.Errorf("failed to write playlists: %w", err)
+	}
+	return nil
+}
+
+// Get returns the playlist with the given name
+func (s *Store) Get(name string) (*Playlist, error) {
+	playlists, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	p, ok := playlists[name]
+	if !ok {
+		return nil, fmt.Errorf("playlist '%s' not found", name)
+	}
+
+	return p, nil
+}
+
+// List returns all playlists sorted by name
+func (s *Store) List() ([]*Playlist, error) {
+	playlists, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*Playlist, 0, len(playlists))
+	for _, p := range playlists {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result, nil
+}
+
+// Create adds a new, possibly empty, playlist
+func (s *Store) Create(name string, storyIDs ...string) (*Playlist, error) {
+	playlists, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, exists := playlists[name]; exists {
+		return nil, fmt.Errorf("playlist '%s' already exists", name)
+	}
+
+	now := time.Now()
+	p := &Playlist{Name: name, CreatedAt: now, UpdatedAt: now}
+	p.Add(storyIDs...)
+	playlists[name] = p
+
+	return p, s.save(playlists)
+}
+
+// Save stores changes to an existing playlist
+func (s *Store) Save(p *Playlist) error {
+	playlists, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	playlists[p.Name] = p
+	return s.save(playlists)
+}
+
+// Delete removes a playlist
+func (s *Store) Delete(name string) error {
+	playlists, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	if _, exists := playlists[name]; !exists {
+		return fmt.Errorf("playlist '%s' not found", name)
+	}
+
+	delete(playlists, name)
+	return s.save(playlists)
+}
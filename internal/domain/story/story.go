@@ -1,5 +1,10 @@
 package story
 
+import (
+	"regexp"
+	"strings"
+)
+
 type OnlineResource struct {
 	ID          string            `json:"id"`
 	Name        string            `json:"name"`
@@ -18,4 +23,27 @@ type Item struct {
 	Genre       string `json:"genre"`
 	Duration    string `json:"duration"`
 	Description string `json:"description"`
+	Cover       string `json:"cover,omitempty"` // URL or path of a cover image, if known
+}
+
+// Chapter marks where a section of a story starts
+type Chapter struct {
+	Title  string
+	Offset int // byte offset into Content
+}
+
+// chapterHeading matches lines such as "CHAPTER IV." or "Part 2 - The Wolf"
+var chapterHeading = regexp.MustCompile(`(?m)^[ \t]*((?:CHAPTER|Chapter|BOOK|Book|PART|Part|STORY|Story)[ \t]+(?:[0-9]+|[IVXLCDM]+)\b[^\r\n]*)`)
+
+// Chapters returns the chapter headings found in the story text, in order.
+// Stories without recognisable headings have no chapters.
+func (i Item) Chapters() []Chapter {
+	var chapters []Chapter
+	for _, m := range chapterHeading.FindAllStringSubmatchIndex(i.Content, -1) {
+		chapters = append(chapters, Chapter{
+			Title:  strings.TrimSpace(i.Content[m[2]:m[3]]),
+			Offset: m[2],
+		})
+	}
+	return chapters
 }
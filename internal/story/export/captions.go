@@ -0,0 +1,118 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"storynest/internal/story/tts"
+	"strings"
+	"time"
+)
+
+// Supported caption formats
+const (
+	CaptionsSRT = "srt"
+	CaptionsVTT = "vtt"
+	CaptionsLRC = "lrc"
+)
+
+// Captions returns the sentence timing track for the segments
+func (t *Timeline) Captions() []tts.Cue {
+	return tts.TimingTrack(t.Segments, t.Lengths)
+}
+
+// WriteCaptions saves cues as SubRip, WebVTT or LRC. WebVTT and LRC carry
+// word timings too when the engine reported them, for read-along highlighting.
+func WriteCaptions(cues []tts.Cue, format, path string, opts Options) error {
+	var content string
+	switch format {
+	case CaptionsSRT:
+		content = formatSRT(cues)
+	case CaptionsVTT:
+		content = formatVTT(cues)
+	case CaptionsLRC:
+		content = formatLRC(cues, opts)
+	default:
+		return fmt.Errorf("unsupported caption format %q (use srt, vtt or lrc)", format)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write captions to %s: %w", path, err)
+	}
+	return nil
+}
+
+func formatSRT(cues []tts.Cue) string {
+	var b strings.Builder
+	for i, cue := range cues {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1,
+			clockTime(cue.Start, ","), clockTime(cue.End, ","), captionText(cue.Text))
+	}
+	return b.String()
+}
+
+func formatVTT(cues []tts.Cue) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for _, cue := range cues {
+		fmt.Fprintf(&b, "%s --> %s\n", clockTime(cue.Start, "."), clockTime(cue.End, "."))
+		if len(cue.Words) == 0 {
+			b.WriteString(vttEscape(captionText(cue.Text)))
+		} else {
+			for i, w := range cue.Words {
+				if i > 0 {
+					fmt.Fprintf(&b, " <%s>", clockTime(w.Start, "."))
+				}
+				b.WriteString(vttEscape(w.Text))
+			}
+		}
+		b.WriteString("\n\n")
+	}
+	return b.String()
+}
+
+func formatLRC(cues []tts.Cue, opts Options) string {
+	var b strings.Builder
+	if opts.Title != "" {
+		fmt.Fprintf(&b, "[ti:%s]\n", opts.Title)
+	}
+	if opts.Author != "" {
+		fmt.Fprintf(&b, "[ar:%s]\n", opts.Author)
+	}
+	for _, cue := range cues {
+		fmt.Fprintf(&b, "[%s]", lrcTime(cue.Start))
+		if len(cue.Words) == 0 {
+			b.WriteString(captionText(cue.Text))
+		} else {
+			// Enhanced LRC: a timestamp before every word
+			for i, w := range cue.Words {
+				if i > 0 {
+					b.WriteString(" ")
+				}
+				fmt.Fprintf(&b, "<%s>%s", lrcTime(w.Start), w.Text)
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// clockTime formats d as HH:MM:SS with milliseconds after sep
+func clockTime(d time.Duration, sep string) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
+
+// lrcTime formats d as MM:SS.xx, with minutes running past 59 for long books
+func lrcTime(d time.Duration) string {
+	cs := d.Milliseconds() / 10
+	return fmt.Sprintf("%02d:%02d.%02d", cs/6000, cs/100%60, cs%100)
+}
+
+// captionText folds a sentence onto one line
+func captionText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func vttEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
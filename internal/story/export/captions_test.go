@@ -0,0 +1,76 @@
+package export
+
+import (
+	"storynest/internal/story/tts"
+	"testing"
+	"time"
+)
+
+const ms = time.Millisecond
+
+// plainCues are two sentences from an engine that doesn't report word marks
+var plainCues = []tts.Cue{
+	{Start: 0, End: 1500 * ms, Text: "Once upon\n a time."},
+	{Start: 1500 * ms, End: 3250 * ms, Text: "Bears & <porridge>."},
+}
+
+// markedCues are the same sentences with the time of every word
+var markedCues = []tts.Cue{
+	{Start: 0, End: 1500 * ms, Text: "Once upon a time.", Words: []tts.WordTiming{
+		{Start: 0, Text: "Once"}, {Start: 400 * ms, Text: "upon"}, {Start: 800 * ms, Text: "a"}, {Start: 900 * ms, Text: "time."},
+	}},
+	{Start: 1500 * ms, End: 3250 * ms, Text: "Bears & <porridge>.", Words: []tts.WordTiming{
+		{Start: 1500 * ms, Text: "Bears"}, {Start: 2000 * ms, Text: "&"}, {Start: 2250 * ms, Text: "<porridge>."},
+	}},
+}
+
+func TestCaptionFormats(t *testing.T) {
+	opts := Options{Title: "Goldilocks", Author: "Anon"}
+
+	tests := []struct {
+		name   string
+		format func([]tts.Cue) string
+		cues   []tts.Cue
+		want   string
+	}{
+		{"srt", formatSRT, plainCues, "" +
+			"1\n00:00:00,000 --> 00:00:01,500\nOnce upon a time.\n\n" +
+			"2\n00:00:01,500 --> 00:00:03,250\nBears & <porridge>.\n\n"},
+		{"vtt", formatVTT, plainCues, "WEBVTT\n\n" +
+			"00:00:00.000 --> 00:00:01.500\nOnce upon a time.\n\n" +
+			"00:00:01.500 --> 00:00:03.250\nBears &amp; &lt;porridge&gt;.\n\n"},
+		{"vtt with words", formatVTT, markedCues, "WEBVTT\n\n" +
+			"00:00:00.000 --> 00:00:01.500\nOnce <00:00:00.400>upon <00:00:00.800>a <00:00:00.900>time.\n\n" +
+			"00:00:01.500 --> 00:00:03.250\nBears <00:00:02.000>&amp; <00:00:02.250>&lt;porridge&gt;.\n\n"},
+		{"lrc", func(cues []tts.Cue) string { return formatLRC(cues, opts) }, plainCues, "" +
+			"[ti:Goldilocks]\n[ar:Anon]\n" +
+			"[00:00.00]Once upon a time.\n" +
+			"[00:01.50]Bears & <porridge>.\n"},
+		{"lrc with words", func(cues []tts.Cue) string { return formatLRC(cues, opts) }, markedCues, "" +
+			"[ti:Goldilocks]\n[ar:Anon]\n" +
+			"[00:00.00]<00:00.00>Once <00:00.40>upon <00:00.80>a <00:00.90>time.\n" +
+			"[00:01.50]<00:01.50>Bears <00:02.00>& <00:02.25><porridge>.\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.format(tt.cues); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCaptionTimes(t *testing.T) {
+	// Longer than an hour, as audiobooks are
+	d := time.Hour + 2*time.Minute + 3*time.Second + 456*ms
+
+	if got, want := clockTime(d, ","), "01:02:03,456"; got != want {
+		t.Errorf("SRT time = %s, want %s", got, want)
+	}
+	if got, want := clockTime(d, "."), "01:02:03.456"; got != want {
+		t.Errorf("WebVTT time = %s, want %s", got, want)
+	}
+	if got, want := lrcTime(d), "62:03.45"; got != want {
+		t.Errorf("LRC time = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,334 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"storynest/internal/domain/story"
+	"storynest/internal/story/tts"
+	"strings"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/wav"
+)
+
+// Supported export formats
+const (
+	FormatMP3 = "mp3"
+	FormatWAV = "wav"
+	FormatOGG = "ogg"
+	FormatM4B = "m4b"
+)
+
+// Options describe the audio file to write
+type Options struct {
+	Format      string
+	Output      string
+	Title       string
+	Author      string
+	Description string
+	Cover       []byte // JPEG or PNG image data
+	CoverMIME   string
+	Captions    []string // caption formats to write next to the audio (srt, vtt, lrc)
+}
+
+// Result summarises a finished export
+type Result struct {
+	Duration     float64 // seconds
+	Chapters     []ChapterMark
+	Size         int64
+	CaptionFiles []string
+}
+
+// Write renders the segments into a single audio file with metadata and chapter markers
+func Write(segments []tts.AudioSegment, chapters []story.Chapter, opts Options) (*Result, error) {
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("nothing to export")
+	}
+
+	timeline, err := NewTimeline(segments)
+	if err != nil {
+		return nil, err
+	}
+	marks := timeline.ChapterMarks(chapters)
+
+	switch opts.Format {
+	case FormatMP3:
+		err = writeMP3(segments, marks, opts)
+	case FormatWAV:
+		err = writeWAVFile(segments, opts.Output, &opts)
+	case FormatOGG:
+		err = writeOGG(segments, marks, opts)
+	case FormatM4B:
+		err = writeM4B(segments, marks, opts)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q (use mp3, wav, ogg or m4b)", opts.Format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(opts.Output)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Duration: timeline.Total.Seconds(),
+		Chapters: marks,
+		Size:     info.Size(),
+	}
+
+	if len(opts.Captions) > 0 {
+		cues := timeline.Captions()
+		base := strings.TrimSuffix(opts.Output, filepath.Ext(opts.Output))
+		for _, format := range opts.Captions {
+			path := base + "." + format
+			if err := WriteCaptions(cues, format, path, opts); err != nil {
+				return nil, err
+			}
+			result.CaptionFiles = append(result.CaptionFiles, path)
+		}
+	}
+
+	return result, nil
+}
+
+// writeMP3 joins MP3 segments frame by frame when possible, otherwise encodes with a local tool
+func writeMP3(segments []tts.AudioSegment, marks []ChapterMark, opts Options) error {
+	var audio []byte
+
+	if allFormat(segments, FormatMP3) {
+		var joined bytes.Buffer
+		for _, seg := range segments {
+			joined.Write(stripID3(seg.Data))
+		}
+		audio = joined.Bytes()
+	} else {
+		encoded, err := encodeWithTool(segments, FormatMP3, nil, opts)
+		if err != nil {
+			return err
+		}
+		audio = stripID3(encoded)
+	}
+
+	f, err := os.Create(opts.Output)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", opts.Output, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(buildID3(opts, marks)); err != nil {
+		return fmt.Errorf("failed to write ID3 tag: %w", err)
+	}
+	if _, err := f.Write(audio); err != nil {
+		return fmt.Errorf("failed to write audio: %w", err)
+	}
+
+	return nil
+}
+
+// writeOGG encodes to Ogg Vorbis with a local tool, adding chapters as Vorbis comments
+func writeOGG(segments []tts.AudioSegment, marks []ChapterMark, opts Options) error {
+	encoded, err := encodeWithTool(segments, FormatOGG, marks, opts)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(opts.Output, encoded, 0644)
+}
+
+// writeWAVFile decodes every segment to PCM and writes one WAV file.
+// If opts is given, title and author are stored in a LIST/INFO chunk.
+func writeWAVFile(segments []tts.AudioSegment, path string, opts *Options) error {
+	streamers := make([]beep.Streamer, 0, len(segments))
+	var format beep.Format
+
+	for i, seg := range segments {
+		s, f, err := decodeSegment(seg)
+		if err != nil {
+			return fmt.Errorf("failed to decode segment %d: %w", i, err)
+		}
+		defer s.Close()
+
+		if i == 0 {
+			format = f
+		}
+		var stream beep.Streamer = s
+		if f.SampleRate != format.SampleRate {
+			stream = beep.Resample(4, f.SampleRate, format.SampleRate, s)
+		}
+		streamers = append(streamers, stream)
+	}
+	format.Precision = 2
+
+	out, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer out.Close()
+
+	if err := wav.Encode(out, beep.Seq(streamers...), format); err != nil {
+		return fmt.Errorf("failed to encode WAV: %w", err)
+	}
+
+	if opts != nil {
+		if err := appendWAVInfo(out, opts.Title, opts.Author, opts.Description); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// appendWAVInfo adds a LIST/INFO chunk after the audio and fixes up the RIFF size
+func appendWAVInfo(f *os.File, title, author, comment string) error {
+	var info bytes.Buffer
+	info.WriteString("INFO")
+	for _, field := range []struct{ id, value string }{
+		{"INAM", title},
+		{"IART", author},
+		{"ICMT", comment},
+	} {
+		if field.value == "" {
+			continue
+		}
+		value := append([]byte(field.value), 0)
+		if len(value)%2 == 1 {
+			value = append(value, 0)
+		}
+		info.WriteString(field.id)
+		binary.Write(&info, binary.LittleEndian, uint32(len(value)))
+		info.Write(value)
+	}
+
+	if info.Len() == 4 {
+		return nil
+	}
+
+	end, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	var chunk bytes.Buffer
+	chunk.WriteString("LIST")
+	binary.Write(&chunk, binary.LittleEndian, uint32(info.Len()))
+	chunk.Write(info.Bytes())
+	if _, err := f.Write(chunk.Bytes()); err != nil {
+		return fmt.Errorf("failed to write WAV metadata: %w", err)
+	}
+
+	if _, err := f.Seek(4, io.SeekStart); err != nil {
+		return err
+	}
+	return binary.Write(f, binary.LittleEndian, uint32(end+int64(chunk.Len())-8))
+}
+
+// encodeWithTool writes the audio to a temporary WAV and converts it with ffmpeg
+// (or lame/oggenc), returning the encoded bytes
+func encodeWithTool(segments []tts.AudioSegment, format string, marks []ChapterMark, opts Options) ([]byte, error) {
+	tmpDir, err := os.MkdirTemp("", "storynest-export-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	input := filepath.Join(tmpDir, "story.wav")
+	if err := writeWAVFile(segments, input, nil); err != nil {
+		return nil, err
+	}
+	output := filepath.Join(tmpDir, "story."+format)
+
+	cmd, err := encoderCommand(format, input, output, marks, opts, tmpDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("%s failed: %w\n%s", filepath.Base(cmd.Path), err, strings.TrimSpace(string(out)))
+	}
+
+	return os.ReadFile(output)
+}
+
+// encoderCommand picks a locally installed encoder for the format
+func encoderCommand(format, input, output string, marks []ChapterMark, opts Options, tmpDir string) (*exec.Cmd, error) {
+	if ffmpeg, err := exec.LookPath("ffmpeg"); err == nil {
+		args := []string{"-y", "-loglevel", "error", "-i", input}
+		switch format {
+		case FormatMP3:
+			args = append(args, "-codec:a", "libmp3lame", "-q:a", "4")
+		case FormatOGG:
+			metaFile := filepath.Join(tmpDir, "metadata.txt")
+			if err := os.WriteFile(metaFile, []byte(ffMetadata(opts, marks)), 0644); err != nil {
+				return nil, err
+			}
+			args = append(args, "-i", metaFile, "-map", "0:a", "-map_metadata", "1", "-map_chapters", "1",
+				"-codec:a", "libvorbis", "-q:a", "4")
+		}
+		return exec.Command(ffmpeg, append(args, output)...), nil
+	}
+
+	switch format {
+	case FormatMP3:
+		if lame, err := exec.LookPath("lame"); err == nil {
+			return exec.Command(lame, "--quiet", "-V", "4", input, output), nil
+		}
+	case FormatOGG:
+		if oggenc, err := exec.LookPath("oggenc"); err == nil {
+			args := []string{"--quiet", "-q", "4", "-o", output}
+			if opts.Title != "" {
+				args = append(args, "-t", opts.Title)
+			}
+			if opts.Author != "" {
+				args = append(args, "-a", opts.Author)
+			}
+			for i, mark := range marks {
+				args = append(args,
+					"-c", fmt.Sprintf("CHAPTER%03d=%s", i+1, clockTime(mark.Start, ".")),
+					"-c", fmt.Sprintf("CHAPTER%03dNAME=%s", i+1, mark.Title))
+			}
+			return exec.Command(oggenc, append(args, input)...), nil
+		}
+	}
+
+	return nil, fmt.Errorf("exporting %s from this engine needs ffmpeg installed locally", format)
+}
+
+func allFormat(segments []tts.AudioSegment, format string) bool {
+	for _, seg := range segments {
+		if seg.Format != format {
+			return false
+		}
+	}
+	return true
+}
+
+// ffMetadata builds an FFMETADATA1 file with the title, author and chapters for ffmpeg
+func ffMetadata(opts Options, marks []ChapterMark) string {
+	var b strings.Builder
+	b.WriteString(";FFMETADATA1\n")
+	if opts.Title != "" {
+		fmt.Fprintf(&b, "title=%s\nalbum=%s\n", ffEscape(opts.Title), ffEscape(opts.Title))
+	}
+	if opts.Author != "" {
+		fmt.Fprintf(&b, "artist=%s\n", ffEscape(opts.Author))
+	}
+	if opts.Description != "" {
+		fmt.Fprintf(&b, "comment=%s\n", ffEscape(opts.Description))
+	}
+	for _, mark := range marks {
+		fmt.Fprintf(&b, "\n[CHAPTER]\nTIMEBASE=1/1000\nSTART=%d\nEND=%d\ntitle=%s\n",
+			mark.Start.Milliseconds(), mark.End.Milliseconds(), ffEscape(mark.Title))
+	}
+	return b.String()
+}
+
+// ffEscape escapes the characters FFMETADATA treats specially
+func ffEscape(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "=", "\\=", ";", "\\;", "#", "\\#", "\n", "\\\n").Replace(s)
+}
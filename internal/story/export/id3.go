@@ -0,0 +1,178 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+	"unicode/utf16"
+)
+
+// buildID3 returns an ID3v2.3 tag with title, author, description, cover art and chapter frames
+func buildID3(opts Options, chapters []ChapterMark) []byte {
+	var frames bytes.Buffer
+
+	if opts.Title != "" {
+		frames.Write(id3Frame("TIT2", textPayload(opts.Title)))
+		frames.Write(id3Frame("TALB", textPayload(opts.Title)))
+	}
+	if opts.Author != "" {
+		frames.Write(id3Frame("TPE1", textPayload(opts.Author)))
+	}
+	if opts.Description != "" {
+		frames.Write(id3Frame("COMM", commentPayload(opts.Description)))
+	}
+	if len(opts.Cover) > 0 {
+		frames.Write(id3Frame("APIC", picturePayload(opts.CoverMIME, opts.Cover)))
+	}
+
+	if len(chapters) > 0 {
+		for _, toc := range tocFrames(len(chapters)) {
+			frames.Write(toc)
+		}
+		for i, ch := range chapters {
+			frames.Write(id3Frame("CHAP", chapterPayload(i, ch)))
+		}
+	}
+
+	var tag bytes.Buffer
+	tag.WriteString("ID3")
+	tag.Write([]byte{3, 0, 0}) // version 2.3.0, no flags
+	tag.Write(syncsafe(frames.Len()))
+	tag.Write(frames.Bytes())
+
+	return tag.Bytes()
+}
+
+func id3Frame(id string, payload []byte) []byte {
+	var f bytes.Buffer
+	f.WriteString(id)
+	binary.Write(&f, binary.BigEndian, uint32(len(payload)))
+	f.Write([]byte{0, 0}) // flags
+	f.Write(payload)
+	return f.Bytes()
+}
+
+// textPayload encodes a text frame as UTF-16 with a byte order mark
+func textPayload(s string) []byte {
+	var p bytes.Buffer
+	p.WriteByte(1)
+	p.Write(utf16String(s))
+	return p.Bytes()
+}
+
+func commentPayload(s string) []byte {
+	var p bytes.Buffer
+	p.WriteByte(1)
+	p.WriteString("eng")
+	p.Write(utf16String("")) // short description
+	p.Write(utf16String(s))
+	return p.Bytes()
+}
+
+func picturePayload(mime string, data []byte) []byte {
+	var p bytes.Buffer
+	p.WriteByte(0)
+	p.WriteString(mime)
+	p.WriteByte(0)
+	p.WriteByte(3) // front cover
+	p.WriteByte(0) // empty description
+	p.Write(data)
+	return p.Bytes()
+}
+
+// maxTOCEntries is the most children a CTOC frame can list, as its entry count is one byte
+const maxTOCEntries = 255
+
+// tocFrames returns the table of contents for count chapters. Books with more chapters
+// than one CTOC can hold get a top-level CTOC of nested CTOCs, each listing up to 255.
+// That nests one level deep, so it lists at most 255×255 chapters; any after those
+// still get their CHAP frames but are left out of the table of contents.
+func tocFrames(count int) [][]byte {
+	var chapters []string
+	for i := 0; i < count; i++ {
+		chapters = append(chapters, chapterID(i))
+	}
+	if count <= maxTOCEntries {
+		return [][]byte{id3Frame("CTOC", tocPayload("toc", true, chapters))}
+	}
+
+	var parts []string
+	var nested [][]byte
+	for start := 0; start < count && len(parts) < maxTOCEntries; start += maxTOCEntries {
+		end := min(start+maxTOCEntries, count)
+		id := fmt.Sprintf("toc%d", len(parts)+1)
+		parts = append(parts, id)
+		nested = append(nested, id3Frame("CTOC", tocPayload(id, false, chapters[start:end])))
+	}
+	return append([][]byte{id3Frame("CTOC", tocPayload("toc", true, parts))}, nested...)
+}
+
+func tocPayload(id string, topLevel bool, children []string) []byte {
+	var p bytes.Buffer
+	p.WriteString(id)
+	p.WriteByte(0)
+	flags := byte(0x01) // ordered
+	if topLevel {
+		flags |= 0x02
+	}
+	p.WriteByte(flags)
+	p.WriteByte(byte(len(children)))
+	for _, child := range children {
+		p.WriteString(child)
+		p.WriteByte(0)
+	}
+	return p.Bytes()
+}
+
+func chapterPayload(index int, ch ChapterMark) []byte {
+	var p bytes.Buffer
+	p.WriteString(chapterID(index))
+	p.WriteByte(0)
+	binary.Write(&p, binary.BigEndian, uint32(ch.Start/time.Millisecond))
+	binary.Write(&p, binary.BigEndian, uint32(ch.End/time.Millisecond))
+	binary.Write(&p, binary.BigEndian, uint32(0xFFFFFFFF)) // byte offsets unused
+	binary.Write(&p, binary.BigEndian, uint32(0xFFFFFFFF))
+	p.Write(id3Frame("TIT2", textPayload(ch.Title)))
+	return p.Bytes()
+}
+
+func chapterID(index int) string {
+	return fmt.Sprintf("ch%d", index)
+}
+
+// utf16String encodes s as little-endian UTF-16 with a BOM and a null terminator
+func utf16String(s string) []byte {
+	var b bytes.Buffer
+	b.Write([]byte{0xFF, 0xFE})
+	for _, u := range utf16.Encode([]rune(s)) {
+		binary.Write(&b, binary.LittleEndian, u)
+	}
+	b.Write([]byte{0, 0})
+	return b.Bytes()
+}
+
+// syncsafe encodes a tag size using 7 bits per byte as ID3 requires
+func syncsafe(n int) []byte {
+	return []byte{
+		byte(n>>21) & 0x7F,
+		byte(n>>14) & 0x7F,
+		byte(n>>7) & 0x7F,
+		byte(n) & 0x7F,
+	}
+}
+
+// stripID3 removes a leading ID3v2 tag and a trailing ID3v1 tag from MP3 data,
+// leaving only audio frames that can be safely concatenated
+func stripID3(data []byte) []byte {
+	if len(data) >= 10 && string(data[0:3]) == "ID3" {
+		size := int(data[6])<<21 | int(data[7])<<14 | int(data[8])<<7 | int(data[9])
+		if 10+size <= len(data) {
+			data = data[10+size:]
+		}
+	}
+	if len(data) >= 128 && string(data[len(data)-128:len(data)-125]) == "TAG" {
+		data = data[:len(data)-128]
+	}
+	return data
+}
@@ -0,0 +1,137 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"storynest/internal/domain/story"
+	"storynest/internal/story/tts"
+	"testing"
+	"time"
+)
+
+// ctoc is a parsed CTOC frame
+type ctoc struct {
+	id       string
+	flags    byte
+	children []string
+}
+
+func parseCTOC(t *testing.T, frame []byte) ctoc {
+	t.Helper()
+	if string(frame[:4]) != "CTOC" {
+		t.Fatalf("frame is %q, want CTOC", frame[:4])
+	}
+	payload := frame[10:]
+	end := bytes.IndexByte(payload, 0)
+	toc := ctoc{id: string(payload[:end]), flags: payload[end+1]}
+	count := int(payload[end+2])
+	rest := payload[end+3:]
+	for range count {
+		end := bytes.IndexByte(rest, 0)
+		toc.children = append(toc.children, string(rest[:end]))
+		rest = rest[end+1:]
+	}
+	return toc
+}
+
+func chapterIDs(from, to int) []string {
+	var ids []string
+	for i := from; i < to; i++ {
+		ids = append(ids, fmt.Sprintf("ch%d", i))
+	}
+	return ids
+}
+
+func TestTOCFrames(t *testing.T) {
+	tests := []struct {
+		name     string
+		chapters int
+		want     []ctoc
+	}{
+		{"one chapter", 1, []ctoc{{"toc", 0x03, chapterIDs(0, 1)}}},
+		{"a full table", 255, []ctoc{{"toc", 0x03, chapterIDs(0, 255)}}},
+		{"one too many", 256, []ctoc{
+			{"toc", 0x03, []string{"toc1", "toc2"}},
+			{"toc1", 0x01, chapterIDs(0, 255)},
+			{"toc2", 0x01, chapterIDs(255, 256)},
+		}},
+		{"several nested", 600, []ctoc{
+			{"toc", 0x03, []string{"toc1", "toc2", "toc3"}},
+			{"toc1", 0x01, chapterIDs(0, 255)},
+			{"toc2", 0x01, chapterIDs(255, 510)},
+			{"toc3", 0x01, chapterIDs(510, 600)},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frames := tocFrames(tt.chapters)
+			if len(frames) != len(tt.want) {
+				t.Fatalf("got %d CTOC frames, want %d", len(frames), len(tt.want))
+			}
+			for i, frame := range frames {
+				got := parseCTOC(t, frame)
+				want := tt.want[i]
+				if got.id != want.id || got.flags != want.flags || !slices.Equal(got.children, want.children) {
+					t.Errorf("frame %d is %s (flags %#x, %d children), want %s (flags %#x, %d children)",
+						i, got.id, got.flags, len(got.children), want.id, want.flags, len(want.children))
+				}
+			}
+		})
+	}
+}
+
+func TestStripID3ReadsSyncsafeSize(t *testing.T) {
+	audio := []byte{0xFF, 0xFB, 0x90, 0x64}
+	v1 := append([]byte("TAG"), make([]byte, 125)...)
+
+	for _, size := range []int{0, 1, 127, 128, 16383, 16384, 300000} {
+		t.Run(fmt.Sprint(size), func(t *testing.T) {
+			data := append([]byte("ID3"), 3, 0, 0)
+			data = append(data, syncsafe(size)...)
+			data = append(data, make([]byte, size)...)
+			data = append(data, audio...)
+			data = append(data, v1...)
+
+			if got := stripID3(data); !bytes.Equal(got, audio) {
+				t.Errorf("stripped to %d bytes, want the %d bytes of audio", len(got), len(audio))
+			}
+		})
+	}
+}
+
+func TestChapterMarks(t *testing.T) {
+	// Two segments of 10 bytes of text, 4s and 6s long
+	timeline := &Timeline{
+		Segments: []tts.AudioSegment{{Text: "0123456789", Offset: 0}, {Text: "abcdefghij", Offset: 10}},
+		Starts:   []time.Duration{0, 4 * time.Second},
+		Lengths:  []time.Duration{4 * time.Second, 6 * time.Second},
+		Total:    10 * time.Second,
+	}
+
+	times := []struct {
+		offset int
+		want   time.Duration
+	}{
+		{0, 0},
+		{5, 2 * time.Second},
+		{10, 4 * time.Second},
+		{15, 7 * time.Second},
+		{25, 10 * time.Second},
+	}
+	for _, tt := range times {
+		if got := timeline.TimeAt(tt.offset); got != tt.want {
+			t.Errorf("TimeAt(%d) = %v, want %v", tt.offset, got, tt.want)
+		}
+	}
+
+	// The first heading comes after a preface, which belongs to the first chapter
+	marks := timeline.ChapterMarks([]story.Chapter{{Title: "One", Offset: 5}, {Title: "Two", Offset: 15}})
+	want := []ChapterMark{
+		{Title: "One", Start: 0, End: 7 * time.Second},
+		{Title: "Two", Start: 7 * time.Second, End: 10 * time.Second},
+	}
+	if !slices.Equal(marks, want) {
+		t.Errorf("ChapterMarks = %+v, want %+v", marks, want)
+	}
+}
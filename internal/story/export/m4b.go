@@ -0,0 +1,88 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"storynest/internal/story/tts"
+	"strings"
+)
+
+// m4bBitrate is plenty for speech and keeps long books small
+const m4bBitrate = "64k"
+
+// writeM4B encodes an AAC audiobook with a chapter atom per story section.
+// AAC encoding needs ffmpeg; MP3 segments (e.g. Google's cached chunks) are handed
+// to it as-is, anything else is decoded to a temporary WAV first.
+func writeM4B(segments []tts.AudioSegment, marks []ChapterMark, opts Options) error {
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return fmt.Errorf("M4B export needs ffmpeg installed locally")
+	}
+
+	tmpDir, err := os.MkdirTemp("", "storynest-m4b-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	input, err := writeEncoderInput(segments, tmpDir)
+	if err != nil {
+		return err
+	}
+
+	metaFile := filepath.Join(tmpDir, "metadata.txt")
+	if err := os.WriteFile(metaFile, []byte(ffMetadata(opts, marks)), 0644); err != nil {
+		return err
+	}
+
+	args := []string{"-y", "-loglevel", "error", "-i", input, "-i", metaFile}
+	maps := []string{"-map", "0:a", "-map_metadata", "1", "-map_chapters", "1"}
+
+	if len(opts.Cover) > 0 {
+		coverFile := filepath.Join(tmpDir, "cover"+coverExtension(opts.CoverMIME))
+		if err := os.WriteFile(coverFile, opts.Cover, 0644); err != nil {
+			return err
+		}
+		args = append(args, "-i", coverFile)
+		maps = append(maps, "-map", "2:v", "-c:v", "copy", "-disposition:v", "attached_pic")
+	}
+
+	args = append(args, maps...)
+	args = append(args,
+		"-c:a", "aac", "-b:a", m4bBitrate,
+		"-metadata", "genre=Audiobook",
+		"-movflags", "+faststart",
+		"-f", "mp4", opts.Output)
+
+	if out, err := exec.Command(ffmpeg, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("ffmpeg failed: %w\n%s", err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+// writeEncoderInput stores the segments in one file an external encoder can read.
+// Cached MP3 chunks are joined without re-encoding; other audio becomes a WAV.
+func writeEncoderInput(segments []tts.AudioSegment, dir string) (string, error) {
+	if allFormat(segments, FormatMP3) {
+		var joined bytes.Buffer
+		for _, seg := range segments {
+			joined.Write(stripID3(seg.Data))
+		}
+		path := filepath.Join(dir, "story.mp3")
+		return path, os.WriteFile(path, joined.Bytes(), 0644)
+	}
+
+	path := filepath.Join(dir, "story.wav")
+	return path, writeWAVFile(segments, path, nil)
+}
+
+func coverExtension(mime string) string {
+	if mime == "image/png" {
+		return ".png"
+	}
+	return ".jpg"
+}
@@ -0,0 +1,98 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"storynest/internal/domain/story"
+	"storynest/internal/story/tts"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/mp3"
+	"github.com/faiface/beep/wav"
+)
+
+// Timeline places each rendered segment on the time axis of the exported audio
+type Timeline struct {
+	Segments []tts.AudioSegment
+	Starts   []time.Duration
+	Lengths  []time.Duration
+	Total    time.Duration
+}
+
+// ChapterMark is a chapter with its position in the exported audio
+type ChapterMark struct {
+	Title string
+	Start time.Duration
+	End   time.Duration
+}
+
+// NewTimeline measures every segment so text offsets can be mapped to playback times
+func NewTimeline(segments []tts.AudioSegment) (*Timeline, error) {
+	t := &Timeline{Segments: segments}
+
+	for i, seg := range segments {
+		streamer, format, err := decodeSegment(seg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode segment %d: %w", i, err)
+		}
+		length := format.SampleRate.D(streamer.Len())
+		streamer.Close()
+
+		t.Starts = append(t.Starts, t.Total)
+		t.Lengths = append(t.Lengths, length)
+		t.Total += length
+	}
+
+	return t, nil
+}
+
+// TimeAt estimates when the text at offset is spoken, interpolating within its segment
+func (t *Timeline) TimeAt(offset int) time.Duration {
+	for i := len(t.Segments) - 1; i >= 0; i-- {
+		seg := t.Segments[i]
+		if offset < seg.Offset {
+			continue
+		}
+		within := offset - seg.Offset
+		if within >= len(seg.Text) || len(seg.Text) == 0 {
+			return t.Starts[i] + t.Lengths[i]
+		}
+		return t.Starts[i] + time.Duration(float64(t.Lengths[i])*float64(within)/float64(len(seg.Text)))
+	}
+	return 0
+}
+
+// ChapterMarks converts text chapters into start and end times
+func (t *Timeline) ChapterMarks(chapters []story.Chapter) []ChapterMark {
+	marks := make([]ChapterMark, 0, len(chapters))
+	for i, ch := range chapters {
+		start := t.TimeAt(ch.Offset)
+		if i == 0 {
+			// Anything before the first heading (title page, preface) belongs to the first chapter
+			start = 0
+		}
+		marks = append(marks, ChapterMark{Title: ch.Title, Start: start})
+	}
+	for i := range marks {
+		if i+1 < len(marks) {
+			marks[i].End = marks[i+1].Start
+		} else {
+			marks[i].End = t.Total
+		}
+	}
+	return marks
+}
+
+// decodeSegment opens a segment's audio for streaming
+func decodeSegment(seg tts.AudioSegment) (beep.StreamSeekCloser, beep.Format, error) {
+	switch seg.Format {
+	case "mp3":
+		return mp3.Decode(io.NopCloser(bytes.NewReader(seg.Data)))
+	case "wav":
+		return wav.Decode(bytes.NewReader(tts.FixWAVSizes(seg.Data)))
+	default:
+		return nil, beep.Format{}, fmt.Errorf("unsupported segment format %q", seg.Format)
+	}
+}
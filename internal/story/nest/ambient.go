@@ -0,0 +1,118 @@
+package nest
+
+import (
+	"storynest/internal/cli/scheme/colours"
+	"storynest/internal/story/tts"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// ambientFadeOut is how long the background sound takes to fade away once it's done
+const ambientFadeOut = 10 * time.Second
+
+// applyAmbientFlag remembers --ambient so the background sound starts with playback
+func (sn *StoryNest) applyAmbientFlag(cmd *cobra.Command) {
+	if source, err := cmd.Flags().GetString("ambient"); err == nil && source != "" {
+		sn.ambientSource = source
+	}
+}
+
+// startAmbient begins the background sound from --ambient or the ambient.source setting
+func (sn *StoryNest) startAmbient() {
+	if sn.ambient != nil {
+		return
+	}
+
+	source := sn.ambientSource
+	if source == "" {
+		source = viper.GetString("ambient.source")
+	}
+	if source == "" || source == "off" {
+		return
+	}
+
+	amb, err := tts.NewAmbient(source, viper.GetFloat64("ambient.volume"), viper.GetFloat64("ambient.duck"))
+	if err != nil {
+		colours.Warning.Printf("⚠️ Could not load ambient sound: %v\n", err)
+		return
+	}
+
+	if err := amb.Start(); err != nil {
+		colours.Warning.Printf("⚠️ Could not play ambient sound: %v\n", err)
+		return
+	}
+
+	sn.ambient = amb
+	colours.Info.Printf("🌧️ Playing %s in the background\n", source)
+
+	go sn.duckAmbient(amb)
+}
+
+// duckAmbient keeps the background quieter whenever the narration is speaking
+func (sn *StoryNest) duckAmbient(amb *tts.Ambient) {
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-amb.Done():
+			return
+		case <-sn.ctx.Done():
+			return
+		case <-ticker.C:
+			amb.Duck(sn.engine().IsPlaying())
+		}
+	}
+}
+
+// stopAmbient silences the background sound straight away
+func (sn *StoryNest) stopAmbient() {
+	if sn.ambient != nil {
+		sn.ambient.Stop()
+		sn.ambient = nil
+	}
+}
+
+// lingerAmbient lets the background sound carry on for a while after the stories end, then fades it out.
+// When the sleep timer ended them it fades out straight away, from no louder than it played under the narration.
+func (sn *StoryNest) lingerAmbient() {
+	amb := sn.ambient
+	if amb == nil {
+		return
+	}
+	sn.ambient = nil
+
+	if sn.sleepNearlyUp() {
+		amb.Hush()
+	} else if linger := viper.GetDuration("ambient.linger"); linger > 0 {
+		colours.Info.Printf("🌧️ The background sound will keep playing for %s (press Enter to stop it now)\n", linger)
+
+		lines := sn.inputLines()
+		timeout := time.After(linger)
+	wait:
+		for {
+			select {
+			case <-sn.ctx.Done():
+				break wait
+			case <-timeout:
+				break wait
+			case _, ok := <-lines:
+				if !ok {
+					lines = nil
+					continue
+				}
+				break wait
+			}
+		}
+	}
+
+	amb.FadeOut(ambientFadeOut)
+
+	select {
+	case <-amb.Done():
+	case <-sn.ctx.Done():
+	case <-time.After(ambientFadeOut + time.Second):
+	}
+}
@@ -0,0 +1,54 @@
+package nest
+
+import (
+	"storynest/internal/cli/scheme/colours"
+	"storynest/internal/config"
+	"storynest/internal/domain/bookmark"
+	"storynest/internal/domain/story"
+	"storynest/internal/story/tts"
+)
+
+func (sn *StoryNest) bookmarkStore() *bookmark.Store {
+	return bookmark.NewStore(config.Dir())
+}
+
+// bookmarkOffset returns where a story should resume from, or 0 if it has no bookmark
+func (sn *StoryNest) bookmarkOffset(story story.Item) int {
+	mark, err := sn.bookmarkStore().Get(story.ID)
+	if err != nil || mark == nil {
+		return 0
+	}
+	if mark.Offset <= 0 || mark.Offset >= len(story.Content) {
+		return 0
+	}
+	return mark.Offset
+}
+
+// updateBookmark remembers where a story stopped, or forgets it once the story is finished
+func (sn *StoryNest) updateBookmark(story story.Item, base int, action playbackAction) {
+	store := sn.bookmarkStore()
+
+	switch action {
+	case actionFinished:
+		if err := store.Clear(story.ID); err != nil {
+			colours.Warning.Printf("⚠️ Could not clear bookmark: %v\n", err)
+		}
+	case actionStop, actionSleep:
+		progress, ok := sn.engine().(tts.ProgressEngine)
+		if !ok {
+			return
+		}
+
+		offset := base + progress.Offset()
+		if offset >= len(story.Content) {
+			store.Clear(story.ID)
+			return
+		}
+
+		if err := store.Set(story.ID, offset); err != nil {
+			colours.Warning.Printf("⚠️ Could not save bookmark: %v\n", err)
+			return
+		}
+		colours.Info.Printf("📑 Bookmark saved at %d%%\n", offset*100/len(story.Content))
+	}
+}
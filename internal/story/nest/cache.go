@@ -0,0 +1,374 @@
+package nest
+
+import (
+	"fmt"
+	"sort"
+	"storynest/internal/cli/scheme/colours"
+	"storynest/internal/story/tts"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func (sn *StoryNest) audioCache() tts.CacheManager {
+	return tts.OpenAudioCache()
+}
+
+// showAudioCacheUsage prints how much narration audio is cached for each book,
+// limited to one provider if one is given
+func (sn *StoryNest) showAudioCacheUsage(provider string) {
+	cache := sn.audioCache()
+
+	fmt.Println()
+	colours.Info.Println("🎧 Narration Audio Cache:")
+	if !cache.Enabled() {
+		colours.Warning.Println("  Caching is off, audio is thrown away after each story")
+		colours.Info.Println("  💡 Turn it on with 'storynest settings set tts.cache_enabled true'")
+		return
+	}
+
+	books, err := cache.Books()
+	if err != nil {
+		colours.Error.Printf("❌ Failed to read the audio cache: %v\n", err)
+		return
+	}
+
+	var total, providerTotal int64
+	var shown int
+	for _, book := range books {
+		total += book.Bytes
+		if provider != "" && book.Provider != provider {
+			continue
+		}
+		providerTotal += book.Bytes
+		shown++
+
+		fmt.Printf("  %-28s %8s  %s", sn.cachedBookName(book), formatSize(book.Bytes), lastPlayed(book.LastPlayed))
+		if book.Pinned {
+			colours.Success.Print("  📌 pinned")
+		}
+		fmt.Println()
+	}
+
+	if shown == 0 {
+		fmt.Println("  No audio cached yet")
+	}
+	if provider != "" {
+		fmt.Printf("  %s audio: %s\n", provider, formatSize(providerTotal))
+	}
+
+	limit := "no limit"
+	if cache.Limit() > 0 {
+		limit = formatSize(cache.Limit())
+	}
+	fmt.Printf("  Total: %s of %s in %s\n", formatSize(total), limit, cache.Root())
+}
+
+// cachedBookName names a cached book by its story title when the story is known
+func (sn *StoryNest) cachedBookName(book tts.CachedBook) string {
+	id := storyIDFor(book.Provider, book.BookID)
+	if item := sn.findStoryByID(id); item != nil {
+		return truncateWidth(item.Title, 28)
+	}
+	return id
+}
+
+// storyIDFor is the inverse of extractProviderFromStoryID and extractBookIDFromStoryID
+func storyIDFor(provider, bookID string) string {
+	if provider == "gutenberg" {
+		return "gutenberg-" + bookID
+	}
+	return bookID
+}
+
+func lastPlayed(t time.Time) string {
+	if t.IsZero() {
+		return "never played"
+	}
+	return "played " + t.Format("2006-01-02 15:04")
+}
+
+// formatSize formats a byte count as KB, MB or GB
+func formatSize(bytes int64) string {
+	switch {
+	case bytes >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(bytes)/(1<<30))
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/(1<<20))
+	default:
+		return fmt.Sprintf("%d KB", (bytes+1023)/1024)
+	}
+}
+
+// PinStories keeps the cached audio of stories from ever being evicted
+func (sn *StoryNest) PinStories(cmd *cobra.Command, args []string) {
+	sn.pinStories(args, true)
+}
+
+// UnpinStories lets the cached audio of stories be evicted again
+func (sn *StoryNest) UnpinStories(cmd *cobra.Command, args []string) {
+	sn.pinStories(args, false)
+}
+
+func (sn *StoryNest) pinStories(ids []string, pinned bool) {
+	cache := sn.audioCache()
+	for _, id := range ids {
+		if err := cache.Pin(extractProviderFromStoryID(id), extractBookIDFromStoryID(id), pinned); err != nil {
+			colours.Error.Printf("❌ Failed to update %s: %v\n", id, err)
+			continue
+		}
+		if pinned {
+			colours.Success.Printf("📌 %s will be kept in the audio cache\n", id)
+		} else {
+			colours.Success.Printf("✅ %s can be evicted from the audio cache again\n", id)
+		}
+	}
+}
+
+// ListCache shows every cached book with its voices, size and when it was last played
+func (sn *StoryNest) ListCache(cmd *cobra.Command, args []string) {
+	fmt.Println()
+	colours.Title.Println("🎧 Cached Narration 🎧")
+	fmt.Println()
+
+	books, err := sn.audioCache().Books()
+	if err != nil {
+		colours.Error.Printf("❌ Failed to read the audio cache: %v\n", err)
+		return
+	}
+	if len(books) == 0 {
+		colours.Warning.Println("🔍 No audio cached yet.")
+		colours.Info.Println("💡 Audio is cached as stories are read aloud")
+		return
+	}
+
+	for _, book := range books {
+		colours.Info.Printf("📖 %s", sn.cachedBookName(book))
+		if book.Pinned {
+			colours.Success.Print("  📌 pinned")
+		}
+		fmt.Println()
+		fmt.Printf("  %s · %d files · %s · %s\n", storyIDFor(book.Provider, book.BookID), book.Files, formatSize(book.Bytes), lastPlayed(book.LastPlayed))
+		if len(book.Voices) > 0 {
+			fmt.Printf("  🗣️ %s\n", strings.Join(book.Voices, ", "))
+		}
+	}
+}
+
+// RemoveFromCache deletes the cached audio of stories, or of a whole provider with --provider
+func (sn *StoryNest) RemoveFromCache(cmd *cobra.Command, args []string) {
+	provider, _ := cmd.Flags().GetString("provider")
+	cache := sn.audioCache()
+
+	if provider != "" {
+		removed, err := cache.RemoveProvider(provider)
+		if err != nil {
+			colours.Error.Printf("❌ Failed to remove cached audio: %v\n", err)
+			return
+		}
+		colours.Success.Printf("✅ Removed %d %s books (%s)\n", len(removed), provider, formatSize(totalSize(removed)))
+		return
+	}
+
+	if len(args) == 0 {
+		colours.Error.Println("❌ Name the stories to remove, or a provider with --provider")
+		return
+	}
+
+	for _, id := range args {
+		book, ok, err := cache.Remove(extractProviderFromStoryID(id), extractBookIDFromStoryID(id))
+		switch {
+		case err != nil:
+			colours.Error.Printf("❌ Failed to remove %s: %v\n", id, err)
+		case !ok:
+			colours.Warning.Printf("⚠️ No cached audio for %s\n", id)
+		default:
+			colours.Success.Printf("✅ Removed %s (%s)\n", id, formatSize(book.Bytes))
+		}
+	}
+}
+
+// PruneCache deletes the audio of books that haven't been played for a while
+func (sn *StoryNest) PruneCache(cmd *cobra.Command, args []string) {
+	olderThan, _ := cmd.Flags().GetString("older-than")
+	age, err := parseAge(olderThan)
+	if err != nil {
+		colours.Error.Printf("❌ %v\n", err)
+		return
+	}
+
+	removed, err := sn.audioCache().Prune(age)
+	if err != nil {
+		colours.Error.Printf("❌ Failed to prune the audio cache: %v\n", err)
+		return
+	}
+	if len(removed) == 0 {
+		colours.Info.Printf("✨ Nothing to prune, every book was played in the last %s\n", olderThan)
+		return
+	}
+	for _, book := range removed {
+		fmt.Printf("  🗑️ %s (%s)\n", sn.cachedBookName(book), formatSize(book.Bytes))
+	}
+	colours.Success.Printf("✅ Pruned %d books, freeing %s\n", len(removed), formatSize(totalSize(removed)))
+}
+
+// VerifyCache checks cached audio against the manifests, optionally deleting anything damaged
+func (sn *StoryNest) VerifyCache(cmd *cobra.Command, args []string) {
+	fix, _ := cmd.Flags().GetBool("fix")
+
+	colours.Info.Println("🔎 Checking cached audio...")
+	problems, err := sn.audioCache().Verify(fix)
+	if err != nil {
+		colours.Error.Printf("❌ Failed to verify the audio cache: %v\n", err)
+		return
+	}
+	if len(problems) == 0 {
+		colours.Success.Println("✅ All cached audio is intact")
+		return
+	}
+
+	for _, p := range problems {
+		colours.Warning.Printf("⚠️ %s (%s): %s %s\n", sn.cachedBookName(p.Book), p.Engine, p.File, p.Problem)
+	}
+	if fix {
+		colours.Success.Printf("✅ Removed %d files, they'll be made again next time\n", len(problems))
+	} else {
+		colours.Info.Printf("💡 %d problems found. Remove the damaged files with 'storynest cache verify --fix'\n", len(problems))
+	}
+}
+
+// CacheDiskUsage shows how much space cached audio takes, per provider
+func (sn *StoryNest) CacheDiskUsage(cmd *cobra.Command, args []string) {
+	cache := sn.audioCache()
+	books, err := cache.Books()
+	if err != nil {
+		colours.Error.Printf("❌ Failed to read the audio cache: %v\n", err)
+		return
+	}
+
+	usage := make(map[string]int64)
+	counts := make(map[string]int)
+	var providers []string
+	for _, book := range books {
+		if _, ok := usage[book.Provider]; !ok {
+			providers = append(providers, book.Provider)
+		}
+		usage[book.Provider] += book.Bytes
+		counts[book.Provider]++
+	}
+	sort.Strings(providers)
+
+	for _, provider := range providers {
+		fmt.Printf("%8s  %-12s %d books\n", formatSize(usage[provider]), provider, counts[provider])
+	}
+
+	total := totalSize(books)
+	if cache.Limit() > 0 {
+		fmt.Printf("%8s  total, %d%% of the %s limit\n", formatSize(total), total*100/cache.Limit(), formatSize(cache.Limit()))
+	} else {
+		fmt.Printf("%8s  total\n", formatSize(total))
+	}
+}
+
+func totalSize(books []tts.CachedBook) int64 {
+	var total int64
+	for _, book := range books {
+		total += book.Bytes
+	}
+	return total
+}
+
+// parseAge understands durations such as 30d and 2w as well as Go durations like 12h
+func parseAge(s string) (time.Duration, error) {
+	days := map[string]int{"d": 1, "w": 7}
+	for suffix, n := range days {
+		if count, ok := strings.CutSuffix(s, suffix); ok {
+			value, err := strconv.Atoi(count)
+			if err != nil || value < 0 {
+				break
+			}
+			return time.Duration(value*n) * 24 * time.Hour, nil
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("'%s' isn't an age such as 30d, 2w or 12h", s)
+	}
+	return d, nil
+}
+
+// AddCacheCommands adds the audio cache commands to the CLI
+func (sn *StoryNest) AddCacheCommands(rootCmd *cobra.Command) {
+	// Cache parent command
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "🎧 Manage cached narration audio",
+		Long:  "List, remove, prune and check the audio kept so stories replay without synthesizing them again",
+		Run:   sn.ListCache,
+	}
+
+	// List subcommand
+	lsCmd := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "📋 List cached books",
+		Long:    "Show every cached book with its voices, size and when it was last played",
+		Run:     sn.ListCache,
+	}
+
+	// Remove subcommand
+	rmCmd := &cobra.Command{
+		Use:   "rm [story-id...]",
+		Short: "🗑️ Remove cached audio",
+		Long:  "Remove the cached audio of stories, or of every book from a provider",
+		Run:   sn.RemoveFromCache,
+	}
+	rmCmd.Flags().String("provider", "", "Remove every book from this provider, e.g. gutenberg")
+
+	// Prune subcommand
+	pruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "✂️ Remove audio that hasn't been played for a while",
+		Long:  "Remove the audio of books not played for longer than --older-than. Pinned books are kept.",
+		Run:   sn.PruneCache,
+	}
+	pruneCmd.Flags().String("older-than", "30d", "Age such as 30d, 2w or 12h")
+
+	// Verify subcommand
+	verifyCmd := &cobra.Command{
+		Use:   "verify",
+		Short: "🔎 Check cached audio for damage",
+		Long:  "Check every cached file against its book's manifest",
+		Run:   sn.VerifyCache,
+	}
+	verifyCmd.Flags().Bool("fix", false, "Delete damaged or unlisted files so they are made again")
+
+	// Disk usage subcommand
+	duCmd := &cobra.Command{
+		Use:   "du",
+		Short: "💾 Show how much space cached audio takes",
+		Run:   sn.CacheDiskUsage,
+	}
+
+	// Pin subcommands
+	pinCmd := &cobra.Command{
+		Use:   "pin <story-id...>",
+		Short: "📌 Keep a story's audio cached",
+		Long:  "Never evict the cached audio of these stories when the cache is over its size limit",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   sn.PinStories,
+	}
+
+	unpinCmd := &cobra.Command{
+		Use:   "unpin <story-id...>",
+		Short: "🔓 Let a story's cached audio be evicted again",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   sn.UnpinStories,
+	}
+
+	cacheCmd.AddCommand(lsCmd, rmCmd, pruneCmd, verifyCmd, duCmd, pinCmd, unpinCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
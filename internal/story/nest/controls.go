@@ -0,0 +1,423 @@
+package nest
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"storynest/internal/cli/scheme/colours"
+	"storynest/internal/domain/story"
+	"storynest/internal/story/tts"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
+)
+
+// statusRefresh is how often the status line redraws while a story plays
+const statusRefresh = time.Second
+
+const (
+	volumeStep = 0.1
+	speedStep  = 0.1
+	minSpeed   = 0.5
+	maxSpeed   = 2.0
+)
+
+// keyCommands maps key presses onto the same commands that can be typed in line mode
+var keyCommands = map[string]string{
+	"space": "p",
+	"p":     "p",
+	"left":  "<",
+	"right": ">",
+	"up":    "<<",
+	"down":  ">>",
+	"pgup":  "<<<",
+	"pgdn":  ">>>",
+	"+":     "+",
+	"=":     "+",
+	"-":     "-",
+	"[":     "[",
+	"]":     "]",
+	"q":     "s",
+	"s":     "s",
+	"n":     "n",
+	"b":     "b",
+	"r":     "r",
+	"t":     "t",
+	"?":     "?",
+	"h":     "?",
+}
+
+// keyCommand returns the command a key press stands for, leaving out seeking if the engine can't seek
+func keyCommand(key string, caps tts.Capabilities) (string, bool) {
+	command, known := keyCommands[key]
+	if !known || (isSeekCommand(command) && !caps.Seek) {
+		return "", false
+	}
+	return command, true
+}
+
+// isSeekCommand reports whether a command moves playback back or forward
+func isSeekCommand(command string) bool {
+	switch command {
+	case "<", ">", "<<", ">>", "<<<", ">>>", "back", "forward", "fwd":
+		return true
+	}
+	return false
+}
+
+// playback is the state of the story being played, shared by the controls and the status line
+type playback struct {
+	item   story.Item
+	queued bool
+	base   int // offset in the story the engine was last started from
+
+	paused   bool
+	sleeping bool
+	chunk    int
+	chunks   int
+
+	started   time.Time
+	pausedAt  time.Time
+	pausedFor time.Duration
+}
+
+func newPlayback(item story.Item, queued bool) *playback {
+	return &playback{item: item, queued: queued, started: time.Now()}
+}
+
+// setPaused records a pause or resume so paused time isn't counted as listening
+func (pb *playback) setPaused(paused bool) {
+	if paused == pb.paused {
+		return
+	}
+	pb.paused = paused
+	if paused {
+		pb.pausedAt = time.Now()
+	} else {
+		pb.pausedFor += time.Since(pb.pausedAt)
+	}
+}
+
+// elapsed is how long the story has been playing, not counting pauses
+func (pb *playback) elapsed() time.Duration {
+	end := time.Now()
+	if pb.paused {
+		end = pb.pausedAt
+	}
+	return end.Sub(pb.started) - pb.pausedFor
+}
+
+// finished is the action for a story that played to the end
+func (pb *playback) finished() playbackAction {
+	if pb.sleeping {
+		return actionSleep
+	}
+	return actionFinished
+}
+
+// engineSwitchNotice says which engine has taken over reading, and why
+func engineSwitchNotice(ev tts.Event) string {
+	if ev.Err == nil {
+		return fmt.Sprintf("🔁 Reading with %s again", ev.Engine)
+	}
+	return fmt.Sprintf("🔁 %v, carrying on with %s", ev.Err, ev.Engine)
+}
+
+// controlsHelp describes the playback controls the engine supports, as keys or typed commands
+func controlsHelp(queued, keys bool, caps tts.Capabilities) string {
+	if keys {
+		var help []string
+		if caps.Pause {
+			help = append(help, "space pause")
+		}
+		if caps.Seek {
+			help = append(help, "←/→ sentence", "↑/↓ paragraph", "PgUp/PgDn chapter")
+		}
+		if caps.CanSetVolume() {
+			help = append(help, "+/- volume")
+		}
+		if caps.CanSetSpeed() {
+			help = append(help, "[/] speed")
+		}
+		help = append(help, "r restart", "t sleep timer", "q stop")
+		if queued {
+			help = append(help, "n/b next/previous story")
+		}
+		return strings.Join(help, " · ")
+	}
+
+	help := "ℹ️  Use "
+	if caps.Pause {
+		help += "'p' for pause/resume, "
+	}
+	if caps.Seek {
+		help += "'<'/'>' for a sentence, '<<'/'>>' for a paragraph, '<<<'/'>>>' for a chapter, "
+	}
+	if caps.CanSetVolume() {
+		help += "'+'/'-' for volume, "
+	}
+	if caps.CanSetSpeed() {
+		help += "'['/']' for speed, "
+	}
+	help += "'r' to restart, "
+	if queued {
+		help += "'n' for next, 'b' for previous story, "
+	}
+	return help + "'t [minutes]' for sleep timer, 's' to stop"
+}
+
+// controlsPrompt is the short reminder of the controls shown while a story plays line by line
+func controlsPrompt(queued bool, caps tts.Capabilities) string {
+	prompt := "\n⏸️  Press "
+	if caps.Pause {
+		prompt += "'p' to pause/resume, "
+	}
+	if caps.Seek {
+		prompt += "'<'/'>' to go back/forward, "
+	}
+	if queued {
+		prompt += "'n' for next, 'b' for previous story, "
+	} else {
+		prompt += "'r' to restart, "
+	}
+	return prompt + "'t' for sleep timer, 's' to stop: "
+}
+
+// runCommand carries out a playback control. done is set when playback should end with the given action.
+func (sn *StoryNest) runCommand(pb *playback, command, arg string, help string) (action playbackAction, restartAt int, done bool) {
+	switch command {
+	case "p", "pause":
+		if !sn.engine().Capabilities().Pause {
+			colours.Warning.Printf("⚠️ The %s engine can't pause\n", sn.getCurrentEngineName())
+			break
+		}
+		// The Paused/Resumed event confirms it
+		if pb.paused {
+			sn.engine().Resume()
+		} else {
+			sn.engine().Pause()
+		}
+	case "s", "stop":
+		sn.engine().Stop()
+		sn.stopAmbient()
+		colours.Warning.Println("⏹️  Stopped")
+		return actionStop, 0, true
+	case "n", "next":
+		if pb.queued {
+			sn.engine().Stop()
+			colours.Info.Println("⏭️  Skipping to next story")
+			return actionNext, 0, true
+		}
+		colours.Info.Println(help)
+	case "b", "prev", "previous":
+		if pb.queued {
+			sn.engine().Stop()
+			colours.Info.Println("⏮️  Going back")
+			return actionPrevious, 0, true
+		}
+		colours.Info.Println(help)
+	case "r", "restart":
+		sn.engine().Stop()
+		colours.Info.Println("⏮️  Starting again from the beginning")
+		return actionRestart, 0, true
+	case "<", ">", "<<", ">>", "<<<", ">>>", "back", "forward", "fwd":
+		if !sn.engine().Capabilities().Seek {
+			colours.Warning.Printf("⚠️ The %s engine can't seek\n", sn.getCurrentEngineName())
+			break
+		}
+		unit, dir, _ := parseSeek(command, arg)
+		if restartAt, restart := sn.seek(pb.item, pb.base, unit, dir); restart {
+			sn.engine().Stop()
+			return actionRestart, restartAt, true
+		}
+	case "+", "louder":
+		sn.changeVolume(volumeStep)
+	case "-", "quieter":
+		sn.changeVolume(-volumeStep)
+	case "[", "slower":
+		sn.changeSpeed(-speedStep)
+	case "]", "faster":
+		sn.changeSpeed(speedStep)
+	case "t", "timer", "sleep":
+		sn.toggleSleepTimer(arg)
+	case "":
+	default:
+		colours.Info.Println(help)
+	}
+	return 0, 0, false
+}
+
+// changeVolume nudges the narration volume up or down
+func (sn *StoryNest) changeVolume(delta float64) {
+	volume, err := sn.stepVolume(delta)
+	if err != nil {
+		colours.Error.Printf("❌ Could not change the volume: %v\n", err)
+		return
+	}
+	colours.Info.Printf("🔊 Volume %d%%\n", int(math.Round(volume*100)))
+}
+
+// changeSpeed nudges the reading speed. The part playing finishes as it is; the parts
+// after it are made again at the new speed.
+func (sn *StoryNest) changeSpeed(delta float64) {
+	speed, err := sn.stepSpeed(delta)
+	if err != nil {
+		colours.Error.Printf("❌ Could not change the speed: %v\n", err)
+		return
+	}
+	colours.Info.Println(speedNotice(speed))
+}
+
+// speedNotice says what a speed change does
+func speedNotice(speed float64) string {
+	return fmt.Sprintf("🐇 Speed %.1fx from the next part", speed)
+}
+
+// stepVolume moves the volume by delta and returns the new level
+func (sn *StoryNest) stepVolume(delta float64) (float64, error) {
+	caps := sn.engine().Capabilities()
+	if !caps.CanSetVolume() {
+		return sn.volume, fmt.Errorf("the %s engine plays at one volume", sn.getCurrentEngineName())
+	}
+	volume := math.Max(caps.Volume.Min, math.Min(caps.Volume.Max, math.Round((sn.volume+delta)*10)/10))
+	if err := sn.engine().SetVolume(volume); err != nil {
+		return sn.volume, err
+	}
+	sn.volume = volume
+	return volume, nil
+}
+
+// stepSpeed moves the speed by delta and returns the new speed
+func (sn *StoryNest) stepSpeed(delta float64) (float64, error) {
+	caps := sn.engine().Capabilities()
+	if !caps.CanSetSpeed() {
+		return sn.speed, fmt.Errorf("this voice reads at one speed")
+	}
+	lowest, highest := math.Max(minSpeed, caps.Speed.Min), math.Min(maxSpeed, caps.Speed.Max)
+	speed := math.Max(lowest, math.Min(highest, math.Round((sn.speed+delta)*10)/10))
+	if err := sn.engine().SetSpeed(speed); err != nil {
+		return sn.speed, err
+	}
+	sn.speed = speed
+	return speed, nil
+}
+
+// waitForKeys is waitForUserInput for a terminal in key mode. A status line at the
+// bottom shows how playback is going and single keys control it.
+func (sn *StoryNest) waitForKeys(events <-chan tts.Event, pb *playback) (playbackAction, int) {
+	caps := sn.engine().Capabilities()
+	help := controlsHelp(pb.queued, true, caps)
+	keys := sn.inputKeys()
+	sleep := sn.sleepExpired()
+
+	ticker := time.NewTicker(statusRefresh)
+	defer ticker.Stop()
+	defer sn.hideStatus()
+
+	sn.drawStatus(pb)
+	for {
+		select {
+		case <-sn.ctx.Done():
+			return actionStop, 0
+		case <-ticker.C:
+		case ev := <-events:
+			switch ev.Type {
+			case tts.EventChunkStarted:
+				pb.chunk, pb.chunks = ev.Chunk, ev.Chunks
+			case tts.EventPaused:
+				pb.setPaused(true)
+			case tts.EventResumed:
+				pb.setPaused(false)
+			case tts.EventError:
+				sn.hideStatus()
+				colours.Error.Printf("❌ TTS Error: %v\n", ev.Err)
+			case tts.EventEngineSwitched:
+				sn.hideStatus()
+				colours.Warning.Println(engineSwitchNotice(ev))
+			case tts.EventFinished:
+				return pb.finished(), 0
+			}
+		case <-sleep:
+			sleep = nil
+			pb.sleeping = true
+			sn.hideStatus()
+			colours.Prompt.Println("😴 Sleep timer finished, stopping at the end of this part...")
+			sn.stopForSleep()
+		case key, ok := <-keys:
+			if !ok {
+				// stdin closed, just let the story play out
+				keys = nil
+				continue
+			}
+			command, known := keyCommand(key, caps)
+			if !known {
+				continue
+			}
+
+			sn.hideStatus()
+			if action, restartAt, done := sn.runCommand(pb, command, "", help); done {
+				return action, restartAt
+			}
+			sleep = sn.sleepExpired()
+		}
+		sn.drawStatus(pb)
+	}
+}
+
+// drawStatus rewrites the status line in place. The read-along view draws the whole
+// screen itself, so there is no status line while it is showing.
+func (sn *StoryNest) drawStatus(pb *playback) {
+	if sn.follow {
+		return
+	}
+
+	icon := "▶️"
+	if pb.paused {
+		icon = "⏸️"
+	}
+
+	parts := []string{icon + " " + pb.item.Title, formatClock(pb.elapsed())}
+	if pb.chunks > 1 {
+		parts = append(parts, fmt.Sprintf("part %d/%d", pb.chunk+1, pb.chunks))
+	}
+	if sn.sleep != nil {
+		parts = append(parts, "😴 "+formatClock(sn.sleep.remaining()))
+	}
+	parts = append(parts, fmt.Sprintf("🔊 %d%%", int(math.Round(sn.volume*100))), fmt.Sprintf("%.1fx", sn.speed), "? help")
+
+	// A status line that wraps can't be redrawn in place
+	line := strings.Join(parts, " · ")
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 8 {
+		line = truncateWidth(line, width-6)
+	}
+
+	fmt.Print("\r\033[K" + colours.Info.Sprint(line))
+}
+
+// hideStatus wipes the status line, if there is one, so messages can be printed in its place
+func (sn *StoryNest) hideStatus() {
+	if !sn.follow {
+		clearStatus()
+	}
+}
+
+// clearStatus wipes the status line so messages can be printed in its place
+func clearStatus() {
+	fmt.Print("\r\033[K")
+}
+
+// formatClock formats d as m:ss, or h:mm:ss for an hour or more
+func formatClock(d time.Duration) string {
+	s := int(d.Round(time.Second) / time.Second)
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
+
+// truncateWidth shortens s to at most n terminal columns, marking the cut with an ellipsis
+func truncateWidth(s string, n int) string {
+	return runewidth.Truncate(s, n, "…")
+}
@@ -0,0 +1,138 @@
+package nest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"storynest/internal/cli/scheme/colours"
+	"storynest/internal/story/export"
+	"storynest/internal/story/tts"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// ExportStory renders a story through the current TTS engine into a single audio file
+func (sn *StoryNest) ExportStory(cmd *cobra.Command, args []string) {
+	storyID := args[0]
+
+	selectedStory := sn.findStoryByID(storyID)
+	if selectedStory == nil {
+		colours.Error.Printf("❌ Story with ID '%s' not found!\n", storyID)
+		return
+	}
+
+	renderer, ok := sn.engine().(tts.RenderingEngine)
+	if !ok {
+		colours.Error.Println("❌ The current TTS engine can't render audio to a file. Try any engine other than mock.")
+		return
+	}
+
+	output, _ := cmd.Flags().GetString("output")
+	format, _ := cmd.Flags().GetString("format")
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(output)), ".")
+	}
+	if format == "" {
+		format = export.FormatMP3
+	}
+	if output == "" {
+		output = fmt.Sprintf("%s.%s", storyID, format)
+	}
+
+	opts := export.Options{
+		Format:      format,
+		Output:      output,
+		Title:       selectedStory.Title,
+		Author:      selectedStory.Author,
+		Description: selectedStory.Description,
+	}
+
+	captions, _ := cmd.Flags().GetStringSlice("captions")
+	for _, c := range captions {
+		opts.Captions = append(opts.Captions, strings.ToLower(strings.TrimSpace(c)))
+	}
+
+	coverSource, _ := cmd.Flags().GetString("cover")
+	if coverSource == "" {
+		coverSource = selectedStory.Cover
+	}
+	if coverSource != "" {
+		cover, err := loadCover(coverSource)
+		if err != nil {
+			colours.Warning.Printf("⚠️ Skipping cover art: %v\n", err)
+		} else {
+			opts.Cover = cover
+			opts.CoverMIME = http.DetectContentType(cover)
+		}
+	}
+
+	chapters := selectedStory.Chapters()
+
+	sn.engine().SetBookContext(extractProviderFromStoryID(storyID), extractBookIDFromStoryID(storyID))
+
+	colours.Info.Printf("🎙️ Rendering '%s' to %s...\n", selectedStory.Title, format)
+	segments, err := renderer.Render(sn.ctx, selectedStory.Content)
+	if sn.ctx.Err() != nil {
+		colours.Warning.Println("⏸️ Export stopped")
+		return
+	}
+	if err != nil {
+		colours.Error.Printf("❌ Failed to render story: %v\n", err)
+		return
+	}
+
+	result, err := export.Write(segments, chapters, opts)
+	if err != nil {
+		colours.Error.Printf("❌ Failed to export story: %v\n", err)
+		return
+	}
+
+	duration := time.Duration(result.Duration * float64(time.Second)).Round(time.Second)
+	colours.Success.Printf("✅ Saved %s (%s, %.1f MB)\n", output, duration, float64(result.Size)/(1024*1024))
+	if len(result.Chapters) > 0 {
+		colours.Info.Printf("📑 %d chapter markers\n", len(result.Chapters))
+	}
+	for _, path := range result.CaptionFiles {
+		colours.Success.Printf("📝 Saved captions to %s\n", path)
+	}
+}
+
+// loadCover reads cover art from a URL or a local file
+func loadCover(source string) ([]byte, error) {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		client := &http.Client{Timeout: 30 * time.Second}
+		resp, err := client.Get(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch cover: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("cover request returned status: %d", resp.StatusCode)
+		}
+		return io.ReadAll(resp.Body)
+	}
+
+	return os.ReadFile(source)
+}
+
+// AddExportCommands adds the export command to the CLI
+func (sn *StoryNest) AddExportCommands(rootCmd *cobra.Command) {
+	exportCmd := &cobra.Command{
+		Use:   "export <story-id>",
+		Short: "💾 Save a story as an audio file",
+		Long:  "Render a whole story with the current TTS engine into one MP3, WAV, OGG or M4B audiobook file for offline players",
+		Args:  cobra.ExactArgs(1),
+		Run:   sn.ExportStory,
+	}
+	exportCmd.Flags().String("format", "", "Audio format: mp3, wav, ogg or m4b (defaults to the output file's extension)")
+	exportCmd.Flags().StringP("output", "o", "", "Output file (defaults to <story-id>.<format>)")
+	exportCmd.Flags().StringSlice("captions", nil, "Also write read-along captions: srt, vtt and/or lrc")
+	exportCmd.Flags().String("cover", "", "Cover image file or URL to embed (MP3 and M4B)")
+
+	rootCmd.AddCommand(exportCmd)
+}
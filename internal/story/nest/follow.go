@@ -0,0 +1,226 @@
+package nest
+
+import (
+	"fmt"
+	"os"
+	"storynest/internal/cli/scheme/colours"
+	"storynest/internal/domain/story"
+	"storynest/internal/story/tts"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// followRefresh is how often the read-along view checks what is being spoken
+const followRefresh = 150 * time.Millisecond
+
+// textLine is one wrapped line of the story as byte offsets into the text
+type textLine struct {
+	start, end int
+}
+
+// followView pages the story text in the terminal and highlights what is being read
+type followView struct {
+	title  string
+	text   string
+	lines  []textLine
+	width  int
+	height int
+	footer string
+}
+
+// applyFollowFlag remembers --follow so stories are shown while they are read
+func (sn *StoryNest) applyFollowFlag(cmd *cobra.Command) {
+	if follow, err := cmd.Flags().GetBool("follow"); err == nil && follow {
+		sn.follow = true
+	}
+}
+
+// followAlong keeps the read-along view in step with the engine until the returned stop func is called.
+// offset is where in the story playback started, since the engine reports positions in what it was given.
+func (sn *StoryNest) followAlong(item story.Item, offset int, queued bool) func() {
+	if !sn.follow {
+		return func() {}
+	}
+
+	follower, ok := sn.engine().(tts.FollowEngine)
+	if !ok {
+		colours.Warning.Println("⚠️ The current TTS engine can't report its progress, so read-along is off")
+		return func() {}
+	}
+
+	caps := sn.engine().Capabilities()
+	footer := "t sleep timer · s stop (then Enter)"
+	if queued {
+		footer = "n next · b previous · " + footer
+	}
+	if caps.Pause {
+		footer = "p pause/resume · " + footer
+	}
+	if keysAvailable() {
+		footer = controlsHelp(queued, true, caps)
+	}
+	view := newFollowView(item.Title, item.Content, footer)
+
+	stop := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+
+		ticker := time.NewTicker(followRefresh)
+		defer ticker.Stop()
+
+		lastStart, lastEnd := -1, -1
+		for {
+			select {
+			case <-stop:
+				return
+			case <-sn.ctx.Done():
+				return
+			case <-ticker.C:
+				start, end, ok := follower.Spoken()
+				if !ok {
+					continue
+				}
+				start, end = start+offset, end+offset
+				if start == lastStart && end == lastEnd {
+					continue
+				}
+				lastStart, lastEnd = start, end
+				view.draw(start, end)
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-finished
+	}
+}
+
+func newFollowView(title, text, footer string) *followView {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		width, height = 80, 24
+	}
+
+	v := &followView{
+		title:  title,
+		text:   text,
+		width:  width,
+		height: height,
+		footer: footer,
+	}
+	v.lines = wrapText(text, width-2)
+	return v
+}
+
+// draw redraws the page holding the highlighted range. Pages turn automatically
+// as the reading moves past the bottom of the screen.
+func (v *followView) draw(start, end int) {
+	pageSize := v.height - 5 // title, blank line, blank line, footer, prompt
+	if pageSize < 1 {
+		pageSize = 1
+	}
+
+	current := 0
+	for i, line := range v.lines {
+		if line.start <= start {
+			current = i
+		}
+	}
+	first := current / pageSize * pageSize
+	last := first + pageSize
+	if last > len(v.lines) {
+		last = len(v.lines)
+	}
+
+	var b strings.Builder
+	b.WriteString("\033[H\033[2J")
+	b.WriteString(colours.Title.Sprintf("📖 %s", v.title))
+	fmt.Fprintf(&b, "  %s\n\n", colours.Info.Sprintf("page %d/%d", first/pageSize+1, (len(v.lines)+pageSize-1)/pageSize))
+
+	for _, line := range v.lines[first:last] {
+		b.WriteString(" ")
+		b.WriteString(v.highlight(line, start, end))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(colours.Info.Sprint(v.footer))
+	b.WriteString("\n")
+
+	fmt.Print(b.String())
+}
+
+// highlight renders one line with the spoken range picked out
+func (v *followView) highlight(line textLine, start, end int) string {
+	text := v.text[line.start:line.end]
+	if end <= line.start || start >= line.end {
+		return text
+	}
+
+	from := max(start, line.start) - line.start
+	to := min(end, line.end) - line.start
+	return text[:from] + colours.Prompt.Sprint(text[from:to]) + text[to:]
+}
+
+// wrapText breaks text into lines of at most width runes at spaces, keeping the story's own line breaks
+func wrapText(text string, width int) []textLine {
+	if width < 10 {
+		width = 10
+	}
+
+	var lines []textLine
+	lineStart := 0
+	for lineStart <= len(text) {
+		lineEnd := strings.IndexByte(text[lineStart:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(text)
+		} else {
+			lineEnd += lineStart
+		}
+		lines = append(lines, wrapLine(text, lineStart, strings.TrimRightFunc(text[:lineEnd], unicode.IsSpace), width)...)
+		lineStart = lineEnd + 1
+	}
+
+	return lines
+}
+
+// wrapLine wraps text[start:len(upto)], a single line of the story
+func wrapLine(text string, start int, upto string, width int) []textLine {
+	end := len(upto)
+	if start >= end {
+		return []textLine{{start: start, end: start}}
+	}
+
+	var lines []textLine
+	for start < end {
+		cut, count, lastSpace := start, 0, -1
+		for cut < end && count < width {
+			r, size := utf8.DecodeRuneInString(text[cut:])
+			if r == ' ' {
+				lastSpace = cut
+			}
+			cut += size
+			count++
+		}
+		if cut < end && lastSpace > start {
+			cut = lastSpace
+		}
+
+		lines = append(lines, textLine{start: start, end: cut})
+
+		// Skip the spaces the line was broken at
+		for cut < end && text[cut] == ' ' {
+			cut++
+		}
+		start = cut
+	}
+
+	return lines
+}
@@ -0,0 +1,165 @@
+package nest
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// inputBuffer is how many lines or keys are held for a reader that isn't listening yet.
+// Anything typed beyond that is dropped rather than applied to a later story.
+const inputBuffer = 16
+
+// escapeKeys names the terminal escape sequences the playback controls use
+var escapeKeys = map[string]string{
+	"\x1b[A":  "up",
+	"\x1b[B":  "down",
+	"\x1b[C":  "right",
+	"\x1b[D":  "left",
+	"\x1bOA":  "up",
+	"\x1bOB":  "down",
+	"\x1bOC":  "right",
+	"\x1bOD":  "left",
+	"\x1b[5~": "pgup",
+	"\x1b[6~": "pgdn",
+	"\x1b[Z":  "backtab",
+}
+
+// startInput starts the single goroutine that reads stdin, so queued stories and prompts
+// don't compete for input. What is typed is handed out as whole lines, or as single
+// key presses while the terminal is in key mode.
+func (sn *StoryNest) startInput() {
+	sn.inputOnce.Do(func() {
+		lines := make(chan string, inputBuffer)
+		keys := make(chan string, inputBuffer)
+		go func() {
+			defer close(lines)
+			defer close(keys)
+
+			buf := make([]byte, 256)
+			var line []byte
+			for {
+				n, err := os.Stdin.Read(buf)
+				if sn.keyInput.Load() {
+					line = line[:0]
+					for _, key := range decodeKeys(buf[:n]) {
+						deliver(keys, key)
+					}
+				} else {
+					for _, b := range buf[:n] {
+						line = append(line, b)
+						if b == '\n' {
+							deliver(lines, string(line))
+							line = line[:0]
+						}
+					}
+				}
+				if err != nil {
+					return
+				}
+			}
+		}()
+		sn.lines, sn.keys = lines, keys
+	})
+}
+
+// inputLines returns a channel fed with lines typed on stdin
+func (sn *StoryNest) inputLines() <-chan string {
+	sn.startInput()
+	return sn.lines
+}
+
+// inputKeys returns a channel fed with key presses while the terminal is in key mode
+func (sn *StoryNest) inputKeys() <-chan string {
+	sn.startInput()
+	return sn.keys
+}
+
+// enterKeyMode switches stdin to single key presses if it is a terminal.
+// The returned func puts the terminal back how it was.
+func (sn *StoryNest) enterKeyMode() (func(), bool) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, false
+	}
+
+	sn.startInput()
+	restore, ok := keyMode(fd)
+	if !ok {
+		return nil, false
+	}
+	sn.keyInput.Store(true)
+
+	// Forget anything typed before the controls were shown
+	drain(sn.keys)
+	drain(sn.lines)
+
+	return func() {
+		sn.keyInput.Store(false)
+		restore()
+	}, true
+}
+
+// keysAvailable reports whether playback can be controlled with single key presses
+func keysAvailable() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// decodeKeys splits what the terminal sent into key names such as "space", "left" or "q"
+func decodeKeys(data []byte) []string {
+	var keys []string
+	for len(data) > 0 {
+		key, size := decodeKey(data)
+		keys = append(keys, key)
+		data = data[size:]
+	}
+	return keys
+}
+
+func decodeKey(data []byte) (string, int) {
+	switch data[0] {
+	case 0x1b:
+		for seq, key := range escapeKeys {
+			if bytes.HasPrefix(data, []byte(seq)) {
+				return key, len(seq)
+			}
+		}
+		return "esc", 1
+	case ' ':
+		return "space", 1
+	case '\r', '\n':
+		return "enter", 1
+	case '\t':
+		return "tab", 1
+	case 0x7f, 0x08:
+		return "backspace", 1
+	}
+
+	r, size := utf8.DecodeRune(data)
+	return strings.ToLower(string(r)), size
+}
+
+// deliver hands v to whoever is reading ch, dropping it if nobody is keeping up
+func deliver(ch chan<- string, v string) {
+	select {
+	case ch <- v:
+	default:
+	}
+}
+
+// drain throws away anything waiting in ch
+func drain(ch <-chan string) {
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
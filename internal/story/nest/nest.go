@@ -17,10 +17,13 @@ import (
 	"storynest/internal/story/tts"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 // StoryNest main application structure
@@ -28,39 +31,106 @@ type StoryNest struct {
 	onlineLibrary library.CachedOnlineLibrary
 
 	libraries []library.StoryLibrary
-	Tts       tts.Engine
 	ctx       context.Context
 	Cancel    context.CancelFunc
-}
 
-func NewStoryNest() *StoryNest {
+	engineMu   sync.Mutex // guards the engine, which is started the first time it's needed
+	ttsEngine  tts.Engine
+	engineType tts.EngineType
 
-	engine, err := tts.NewEngine(tts.Config{
-		Type:   tts.EngineTypeAuto.String(),
-		Speed:  1.0,
-		Volume: 1.0,
-		Voice:  "default",
-	})
+	inputOnce sync.Once
+	lines     chan string
+	keys      chan string
+	keyInput  atomic.Bool
 
-	if err != nil {
-		logrus.WithError(err).Fatal("failed to create tts engine")
-	}
+	volume float64
+	speed  float64
+
+	sleep      *sleepTimer
+	sleepAfter time.Duration
+
+	ambient       *tts.Ambient
+	ambientSource string
+
+	follow bool
+}
 
+func NewStoryNest() *StoryNest {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &StoryNest{
 		onlineLibrary: guten.NewGutenbergCache("./cache", 4*24*time.Hour),
 
 		// todo: remove once we have a guten
 		libraries: []library.StoryLibrary{},
-		Tts:       engine,
 		ctx:       ctx,
 		Cancel:    cancel,
+		volume:    viper.GetFloat64("tts.volume"),
+		speed:     viper.GetFloat64("tts.speed"),
+	}
+}
+
+// engine returns the TTS engine, starting it the first time it's needed
+func (sn *StoryNest) engine() tts.Engine {
+	engine, _ := sn.currentEngine()
+	return engine
+}
+
+// currentEngine returns the TTS engine and its type. Engines are checked when something
+// first needs one, so commands that never speak don't wait on the network.
+func (sn *StoryNest) currentEngine() (tts.Engine, tts.EngineType) {
+	sn.engineMu.Lock()
+	defer sn.engineMu.Unlock()
+
+	if sn.ttsEngine == nil {
+		sn.ttsEngine, sn.engineType = sn.startEngine()
+	}
+	return sn.ttsEngine, sn.engineType
+}
+
+// startEngine starts the first engine that passes its health check; the rest of the
+// chain stands by in case it fails
+func (sn *StoryNest) startEngine() (tts.Engine, tts.EngineType) {
+	engineConfig := sn.engineConfig(viper.GetString("tts.type"), viper.GetString("tts.voice"))
+	chain := tts.EngineChain(engineConfig.Type, viper.GetStringSlice("tts.engines"))
+	engine, engineType, failed := tts.StartChain(sn.ctx, engineConfig, chain)
+	for _, status := range failed {
+		colours.Warning.Printf("⚠️ Could not start the %s TTS engine: %v\n", status.Type, status.Err)
+	}
+	if len(failed) > 0 {
+		colours.Info.Printf("💡 Using %s instead. 'storynest tts doctor' shows what each engine needs\n", engineType)
+	}
+	return engine, engineType
+}
+
+// useEngine swaps in an engine the user picked, keeping the rest of the chain behind it
+func (sn *StoryNest) useEngine(engine tts.Engine, engineType tts.EngineType) {
+	if fe, ok := engine.(tts.FallbackEngine); ok {
+		engineConfig := sn.engineConfig(engineType.String(), "default")
+		chain := tts.EngineChain(engineType.String(), viper.GetStringSlice("tts.engines"))
+		fe.SetFallbacks(tts.Fallbacks(engineConfig, engineType, chain))
+	}
+
+	sn.engineMu.Lock()
+	defer sn.engineMu.Unlock()
+	if sn.ttsEngine != nil {
+		sn.ttsEngine.Stop()
+	}
+	sn.ttsEngine, sn.engineType = engine, engineType
+}
+
+// engineConfig is the engine settings for an engine type and voice, at the current speed and volume
+func (sn *StoryNest) engineConfig(engineType, voice string) tts.Config {
+	return tts.Config{
+		Type:   engineType,
+		Speed:  sn.speed,
+		Volume: sn.volume,
+		Voice:  voice,
 	}
 }
 
 func (sn *StoryNest) SetVoice(voice string) error {
 	logrus.WithField("voice", voice).Info("set voice")
-	return sn.Tts.SetVoice(voice)
+	return sn.engine().SetVoice(voice)
 }
 
 func (sn *StoryNest) ShowWelcome() {
@@ -71,6 +141,7 @@ func (sn *StoryNest) ShowWelcome() {
 	fmt.Println("  • storynest list      - Browse available stories")
 	fmt.Println("  • storynest random    - Get a surprise story")
 	fmt.Println("  • storynest read      - Choose a specific story")
+	fmt.Println("  • storynest playlist  - Play a bedtime playlist")
 	fmt.Println("  • storynest libraries - Manage story sources")
 	fmt.Println("  • storynest settings  - Configure voice settings")
 	fmt.Println()
@@ -220,27 +291,57 @@ func (sn *StoryNest) ReadRandomStory(cmd *cobra.Command, args []string) {
 	colours.Prompt.Println("🎲 Random Story Selection! 🎲")
 	fmt.Println()
 
-	voice, _ := cmd.Flags().GetString("voice")
-	if err := sn.Tts.SetVoice(voice); err != nil {
-		colours.Error.Println("❌ voice '%s' not found on current tts engine!\n")
-	}
+	sn.applyVoiceFlag(cmd)
 
+	sn.applySleepFlag(cmd)
+	sn.applyAmbientFlag(cmd)
 	sn.displayAndReadStory(randomStory)
 }
 
+// applyVoiceFlag switches to the voice given with --voice, leaving the configured voice alone without it
+func (sn *StoryNest) applyVoiceFlag(cmd *cobra.Command) {
+	if !cmd.Flags().Changed("voice") {
+		return
+	}
+	voice, _ := cmd.Flags().GetString("voice")
+	if voice == "" {
+		return
+	}
+	if err := sn.engine().SetVoice(voice); err != nil {
+		colours.Error.Printf("❌ voice '%s' not found on current tts engine!\n", voice)
+	}
+}
+
 func (sn *StoryNest) ReadStory(cmd *cobra.Command, args []string) {
 	interactive, _ := cmd.Flags().GetBool("interactive")
 
-	voice, _ := cmd.Flags().GetString("voice")
-	if err := sn.Tts.SetVoice(voice); err != nil {
-		colours.Error.Println("❌ voice '%s' not found on current tts engine!\n")
-	}
+	sn.applyVoiceFlag(cmd)
+
+	sn.applySleepFlag(cmd)
+	sn.applyAmbientFlag(cmd)
+	sn.applyFollowFlag(cmd)
 
 	if len(args) == 0 || interactive {
 		sn.interactiveStorySelection()
 		return
 	}
 
+	// Several IDs build an ad-hoc queue that plays one story after another
+	if len(args) > 1 {
+		stories := make([]story.Item, 0, len(args))
+		for _, storyID := range args {
+			story := sn.findStoryByID(storyID)
+			if story == nil {
+				colours.Error.Printf("❌ Story with ID '%s' not found!\n", storyID)
+				return
+			}
+			stories = append(stories, *story)
+		}
+
+		sn.playQueue(stories, 0, pauseBetweenStories(cmd), nil)
+		return
+	}
+
 	storyID := args[0]
 	story := sn.findStoryByID(storyID)
 
@@ -294,15 +395,15 @@ func (sn *StoryNest) interactiveStorySelection() {
 }
 
 func (sn *StoryNest) displayAndReadStory(story story.Item) {
-	fmt.Println()
-	colours.Title.Printf("📖 %s\n", story.Title)
-	colours.Author.Printf("✍️  by %s\n", story.Author)
-	fmt.Printf("🎯 Age Group: %s | 🎭 Genre: %s | ⏱️ Duration: %s\n",
-		story.AgeGroup, story.Genre, story.Duration)
-	fmt.Printf("💡 %s\n", story.Description)
-	fmt.Println()
+	sn.showStoryHeader(story)
 
-	colours.Prompt.Print("🎧 Ready to listen? Press Enter to start (or 'skip' to just show text): ")
+	offset := sn.bookmarkOffset(story)
+	if offset > 0 {
+		colours.Info.Printf("📑 Last time you got %d%% of the way through\n", offset*100/len(story.Content))
+		colours.Prompt.Print("🎧 Press Enter to carry on (or 'restart' to start over, 'skip' to just show text): ")
+	} else {
+		colours.Prompt.Print("🎧 Ready to listen? Press Enter to start (or 'skip' to just show text): ")
+	}
 	reader := bufio.NewReader(os.Stdin)
 	input, _ := reader.ReadString('\n')
 	input = strings.TrimSpace(input)
@@ -314,34 +415,96 @@ func (sn *StoryNest) displayAndReadStory(story story.Item) {
 		return
 	}
 
+	if strings.ToLower(input) == "restart" {
+		offset = 0
+	}
+
 	fmt.Println()
 	colours.Success.Println("🎵 Starting story playback... 🎵")
 	fmt.Println("💡 Press Ctrl+C to stop anytime")
 	fmt.Println()
 
-	// Start reading the story
-	go func() {
-		if err := sn.Tts.Speak(story.Content); err != nil {
-			colours.Error.Printf("❌ TTS Error: %v\n", err)
-		} else {
-			colours.Success.Println("✅ Story finished! 🌟")
-			colours.Prompt.Println("😴 Sleep tight! 🌙")
-		}
-	}()
+	sn.armSleepTimer()
+	sn.startAmbient()
+	defer sn.lingerAmbient()
 
-	// Set book context for TTS caching if the engine supports it
+	switch sn.playStory(story, offset, false) {
+	case actionFinished:
+		colours.Success.Println("✅ Story finished! 🌟")
+		colours.Prompt.Println("😴 Sleep tight! 🌙")
+	case actionSleep:
+		colours.Prompt.Println("😴 Sleep tight! 🌙")
+	}
+}
+
+func (sn *StoryNest) showStoryHeader(story story.Item) {
+	fmt.Println()
+	colours.Title.Printf("📖 %s\n", story.Title)
+	colours.Author.Printf("✍️  by %s\n", story.Author)
+	fmt.Printf("🎯 Age Group: %s | 🎭 Genre: %s | ⏱️ Duration: %s\n",
+		story.AgeGroup, story.Genre, story.Duration)
+	fmt.Printf("💡 %s\n", story.Description)
+	fmt.Println()
+}
+
+// playStory reads a story aloud from offset and blocks until it finishes or the listener moves on
+func (sn *StoryNest) playStory(story story.Item, offset int, queued bool) playbackAction {
+	// Set book context for TTS caching before any audio is generated
 
 	// Extract provider from story ID
 	provider := extractProviderFromStoryID(story.ID)
 	// Extract book ID from story ID (remove provider prefix)
 	bookID := extractBookIDFromStoryID(story.ID)
 
-	sn.Tts.SetBookContext(provider, bookID)
+	sn.engine().SetBookContext(provider, bookID)
 
 	colours.Info.Printf("🗂️ Using cache: %s/%s\n", provider, bookID)
 
-	// Wait for user input or context cancellation
-	sn.waitForUserInput()
+	// Seeks the engine can't reach on its own start reading again from a new offset
+	pb := newPlayback(story, queued)
+	for {
+		action, restartAt := sn.playFrom(pb, offset)
+		if action != actionRestart {
+			return action
+		}
+		offset = restartAt
+	}
+}
+
+// playFrom speaks the story from offset until it ends, the listener moves on or playback has to restart
+func (sn *StoryNest) playFrom(pb *playback, offset int) (playbackAction, int) {
+	story := pb.item
+	pb.base = offset
+	pb.chunk, pb.chunks = 0, 0
+
+	// Subscribe first so the end of a very short story isn't missed
+	events, unsubscribe := sn.engine().Subscribe()
+	defer unsubscribe()
+
+	// Start reading the story
+	if err := sn.engine().SpeakContext(sn.ctx, story.Content[offset:]); err != nil {
+		if sn.ctx.Err() != nil {
+			return actionStop, 0
+		}
+		colours.Error.Printf("❌ TTS Error: %v\n", err)
+		return actionFinished, 0
+	}
+
+	stopFollowing := sn.followAlong(story, offset, pb.queued)
+
+	// Wait for the story to end, user input or context cancellation
+	action, restartAt := sn.waitForUserInput(events, pb)
+	stopFollowing()
+	if action != actionRestart {
+		sn.updateBookmark(story, offset, action)
+	}
+
+	return action, restartAt
+}
+
+func (sn *StoryNest) isTTSPaused() bool {
+	enhanced, ok := sn.engine().(tts.EnhancedEngine)
+	return ok && enhanced.IsPaused()
 }
 
 // Helper function to extract provider from story ID
@@ -362,34 +525,97 @@ func extractBookIDFromStoryID(storyID string) string {
 	return storyID
 }
 
-func (sn *StoryNest) waitForUserInput() {
-	reader := bufio.NewReader(os.Stdin)
+// playbackAction tells the caller why a story stopped playing
+type playbackAction int
+
+const (
+	actionFinished playbackAction = iota
+	actionNext
+	actionPrevious
+	actionStop
+	actionSleep
+	actionRestart
+)
+
+// waitForUserInput follows playback events and handles the controls until the story is over.
+// It returns actionRestart with an offset when playback must start again from there.
+func (sn *StoryNest) waitForUserInput(events <-chan tts.Event, pb *playback) (playbackAction, int) {
+	if restore, ok := sn.enterKeyMode(); ok {
+		defer restore()
+		return sn.waitForKeys(events, pb)
+	}
+	return sn.waitForLines(events, pb)
+}
+
+// waitForLines is waitForUserInput for when stdin isn't a terminal, so controls are typed a line at a time
+func (sn *StoryNest) waitForLines(events <-chan tts.Event, pb *playback) (playbackAction, int) {
+	caps := sn.engine().Capabilities()
+	prompt := controlsPrompt(pb.queued, caps)
+	help := controlsHelp(pb.queued, false, caps)
+
+	lines := sn.inputLines()
+	sleep := sn.sleepExpired()
+	fmt.Print(prompt)
+
 	for {
 		select {
 		case <-sn.ctx.Done():
-			return
-		default:
-			fmt.Print("\n⏸️  Press 'p' to pause/resume, 's' to stop, or Enter to continue: ")
-			input, _ := reader.ReadString('\n')
-			input = strings.TrimSpace(strings.ToLower(input))
-
-			switch input {
-			case "p", "pause":
-				if sn.Tts.IsPlaying() {
-					sn.Tts.Pause()
-					colours.Warning.Println("⏸️  Paused")
-				} else {
-					sn.Tts.Resume()
-					colours.Success.Println("▶️  Resumed")
+			return actionStop, 0
+		case ev := <-events:
+			switch ev.Type {
+			case tts.EventChunkStarted:
+				pb.chunk, pb.chunks = ev.Chunk, ev.Chunks
+				// The read-along view shows progress itself
+				if !sn.follow && ev.Chunks > 1 {
+					colours.Info.Printf("\n📖 Part %d of %d", ev.Chunk+1, ev.Chunks)
+					fmt.Print(prompt)
 				}
-			case "s", "stop":
-				sn.Tts.Stop()
-				colours.Warning.Println("⏹️  Stopped")
-				return
-			case "":
+			case tts.EventPaused:
+				pb.setPaused(true)
+				colours.Warning.Println("⏸️  Paused")
+				fmt.Print(prompt)
+			case tts.EventResumed:
+				pb.setPaused(false)
+				colours.Success.Println("▶️  Resumed")
+				fmt.Print(prompt)
+			case tts.EventError:
+				fmt.Println()
+				colours.Error.Printf("❌ TTS Error: %v\n", ev.Err)
+			case tts.EventEngineSwitched:
+				fmt.Println()
+				colours.Warning.Println(engineSwitchNotice(ev))
+				fmt.Print(prompt)
+			case tts.EventFinished:
+				fmt.Println()
+				return pb.finished(), 0
+			}
+		case <-sleep:
+			sleep = nil
+			pb.sleeping = true
+			fmt.Println()
+			colours.Prompt.Println("😴 Sleep timer finished, stopping at the end of this part...")
+			sn.stopForSleep()
+		case input, ok := <-lines:
+			if !ok {
+				// stdin closed, just let the story play out
+				lines = nil
 				continue
-			default:
-				colours.Info.Println("ℹ️  Use 'p' for pause/resume, 's' to stop")
+			}
+
+			fields := strings.Fields(strings.ToLower(input))
+			command, arg := "", ""
+			if len(fields) > 0 {
+				command, arg = fields[0], strings.Join(fields[1:], " ")
+			}
+
+			if action, restartAt, done := sn.runCommand(pb, command, arg, help); done {
+				return action, restartAt
+			}
+			sleep = sn.sleepExpired()
+
+			// Pausing reprints the prompt when the engine confirms it
+			if command != "p" && command != "pause" {
+				fmt.Print(prompt)
 			}
 		}
 	}
@@ -412,25 +638,6 @@ func (sn *StoryNest) ManageLibraries(cmd *cobra.Command, args []string) {
 		len(sn.libraries), len(sn.getAllStories()))
 }
 
-func (sn *StoryNest) ConfigureSettings(cmd *cobra.Command, args []string) {
-	fmt.Println()
-	colours.Title.Println("⚙️ TTS Settings ⚙️")
-	fmt.Println()
-
-	colours.Prompt.Println("🎤 Voice Settings:")
-	fmt.Println("  • Current voice: default")
-	fmt.Println("  • Speed: 1.0x")
-	fmt.Println("  • Volume: 100%")
-	fmt.Println()
-
-	colours.Info.Println("💡 In a full implementation, you could:")
-	fmt.Println("  • Choose different voices (child-friendly, storyteller, etc.)")
-	fmt.Println("  • Adjust reading speed")
-	fmt.Println("  • Control volume levels")
-	fmt.Println("  • Select language preferences")
-	fmt.Println("  • Configure audio output devices")
-}
-
 func (sn *StoryNest) getAllStories() []story.Item {
 	var allStories []story.Item
 	for _, library := range sn.libraries {
@@ -560,6 +767,8 @@ func (sn *StoryNest) ShowCacheStatus(cmd *cobra.Command, args []string) {
 		colours.Warning.Println("❌ Cache does not exist")
 		colours.Info.Println("💡 Run 'storynest gutenberg refresh' to create cache")
 	}
+
+	sn.showAudioCacheUsage("gutenberg")
 }
 
 // Add Gutenberg commands to your main.go rootCmd
@@ -679,96 +888,76 @@ func (sn *StoryNest) ConfigureTTSEngine(cmd *cobra.Command, args []string) {
 
 	selectedEngine := engines[choice-1]
 
-	// Create new engine with current config
-	config := tts.Config{
-		Type: string(selectedEngine),
-
-		//todo:
-		Speed:  1.0,
-		Volume: 1.0,
-		Voice:  "default",
-	}
-
-	newEngine, err := tts.NewEngine(config)
+	// Voices belong to an engine, so a new engine starts on its default voice
+	newEngine, err := tts.CheckEngine(sn.ctx, sn.engineConfig(string(selectedEngine), "default"))
 	if err != nil {
-		colours.Error.Printf("❌ Failed to create %s engine: %v\n", selectedEngine, err)
+		colours.Error.Printf("❌ Can't use the %s engine: %v\n", selectedEngine, err)
 		return
 	}
 
-	sn.Tts = newEngine
+	sn.useEngine(newEngine, selectedEngine)
+	if err := saveSettings(map[string]interface{}{"tts.type": selectedEngine.String(), "tts.voice": "default"}); err != nil {
+		colours.Error.Printf("❌ Could not save engine choice: %v\n", err)
+	}
 	colours.Success.Printf("✅ Switched to %s engine\n", selectedEngine)
 
-	// If it's Chirp, show additional configuration options
-	if selectedEngine == tts.EngineTypeGoogleClassic {
-		sn.configureChirpSettings()
-	}
+	sn.configureVoice()
 }
 
-func (sn *StoryNest) configureChirpSettings() {
+// configureVoice lets the user pick a voice for the current engine, narrowed down by language,
+// and a reading speed
+func (sn *StoryNest) configureVoice() {
 	fmt.Println()
-	colours.Title.Println("🌟 Google Chirp TTS Configuration 🌟")
+	colours.Title.Printf("🗣️ %s Voice Configuration 🗣️\n", sn.getCurrentEngineName())
 	fmt.Println()
 
-	// Show voice selection
-	voices, err := sn.Tts.GetAvailableVoices()
+	voices, detailed, err := sn.voiceInfo()
 	if err != nil {
 		colours.Error.Printf("❌ Failed to get available voices: %v\n", err)
 		return
 	}
 
-	colours.Prompt.Println("Available Chirp Voices (recommended for children):")
-	fmt.Println()
-
-	// Group voices by type for better presentation
-	journeyVoices := []string{}
-	neuralVoices := []string{}
-	standardVoices := []string{}
-
-	for _, voice := range voices {
-		if strings.Contains(voice, "Journey") {
-			journeyVoices = append(journeyVoices, voice)
-		} else if strings.Contains(voice, "Neural") {
-			neuralVoices = append(neuralVoices, voice)
-		} else {
-			standardVoices = append(standardVoices, voice)
-		}
-	}
-
-	// Show Journey voices (best for children)
-	if len(journeyVoices) > 0 {
-		colours.Success.Println("🌟 Journey Voices (Best for Children):")
-		for i, voice := range journeyVoices {
-			gender := "Unknown"
-			if strings.Contains(voice, "Journey-F") {
-				gender = "Female"
-			} else if strings.Contains(voice, "Journey-D") || strings.Contains(voice, "Journey-O") {
-				gender = "Male"
+	reader := bufio.NewReader(os.Stdin)
+	if detailed && len(voices) > 10 {
+		colours.Prompt.Print("Language to list voices for, e.g. en or en-GB (or press Enter for all): ")
+		lang, _ := reader.ReadString('\n')
+		if lang = strings.TrimSpace(lang); lang != "" {
+			var matching []tts.VoiceInfo
+			for _, voice := range voices {
+				if voiceMatches(voice, lang, "") {
+					matching = append(matching, voice)
+				}
+			}
+			if len(matching) == 0 {
+				colours.Warning.Printf("⚠️ No voices for '%s', showing them all\n", lang)
+			} else {
+				voices = matching
 			}
-			fmt.Printf("  %d. %s (%s)\n", i+1, voice, gender)
 		}
 		fmt.Println()
 	}
 
-	// Show Neural voices
-	if len(neuralVoices) > 0 {
-		colours.Info.Println("🧠 Neural Voices (High Quality):")
-		for i, voice := range neuralVoices {
-			fmt.Printf("  %d. %s\n", len(journeyVoices)+i+1, voice)
+	// Natural voices first, as they are the nicest to listen to
+	var natural, standard []tts.VoiceInfo
+	for _, voice := range voices {
+		if voice.Natural {
+			natural = append(natural, voice)
+		} else {
+			standard = append(standard, voice)
 		}
-		fmt.Println()
 	}
+	voices = append(natural, standard...)
 
-	// Show standard voices
-	if len(standardVoices) > 0 {
+	if len(natural) > 0 {
+		colours.Success.Println("🌟 Natural Voices (Best for Children):")
+		printVoiceChoices(natural, 1)
+	}
+	if len(standard) > 0 {
 		colours.Info.Println("📢 Standard Voices:")
-		for i, voice := range standardVoices {
-			fmt.Printf("  %d. %s\n", len(journeyVoices)+len(neuralVoices)+i+1, voice)
-		}
-		fmt.Println()
+		printVoiceChoices(standard, len(natural)+1)
 	}
 
-	colours.Prompt.Print("Select voice number (or press Enter for default): ")
-	reader := bufio.NewReader(os.Stdin)
+	colours.Prompt.Print("Select voice number (or press Enter to keep current): ")
 	input, _ := reader.ReadString('\n')
 	input = strings.TrimSpace(input)
 
@@ -779,36 +968,29 @@ func (sn *StoryNest) configureChirpSettings() {
 			return
 		}
 
-		selectedVoice := voices[choice-1]
-		if err := sn.Tts.SetVoice(selectedVoice); err != nil {
+		selectedVoice := voices[choice-1].Name
+		if err := sn.engine().SetVoice(selectedVoice); err != nil {
 			colours.Error.Printf("❌ Failed to set voice: %v\n", err)
 			return
 		}
 
+		if err := saveSettings(map[string]interface{}{"tts.voice": selectedVoice}); err != nil {
+			colours.Error.Printf("❌ Could not save voice: %v\n", err)
+		}
 		colours.Success.Printf("✅ Voice set to: %s\n", selectedVoice)
 	}
 
-	// Configure speed
-	fmt.Println()
-	colours.Prompt.Print("Enter speaking speed (0.25-4.0, current: 1.0): ")
-	speedInput, _ := reader.ReadString('\n')
-	speedInput = strings.TrimSpace(speedInput)
-
-	if speedInput != "" {
-		speed, err := strconv.ParseFloat(speedInput, 64)
-		if err != nil || speed < 0.25 || speed > 4.0 {
-			colours.Error.Println("❌ Speed must be between 0.25 and 4.0")
-		} else {
-			if err := sn.Tts.SetSpeed(speed); err != nil {
-				colours.Error.Printf("❌ Failed to set speed: %v\n", err)
-			} else {
-				colours.Success.Printf("✅ Speed set to: %.2f\n", speed)
-			}
-		}
+	// Configure speed, unless the voice only reads at one
+	caps := sn.engine().Capabilities()
+	if caps.CanSetSpeed() {
+		sn.configureSpeed(reader, caps.Speed)
+	} else {
+		fmt.Println()
+		colours.Info.Printf("💡 This voice reads at one speed (%g)\n", caps.Speed.Min)
 	}
 
 	// Show cache information if available
-	if cacheable, ok := sn.Tts.(tts.CacheableEngine); ok {
+	if cacheable, ok := sn.engine().(tts.CacheableEngine); ok && caps.Caching {
 		fmt.Println()
 		colours.Info.Println("📁 Cache Information:")
 		if stats, err := cacheable.GetCacheStats(); err == nil {
@@ -819,6 +1001,52 @@ func (sn *StoryNest) configureChirpSettings() {
 	}
 }
 
+// configureSpeed asks for a reading speed within the engine's range and saves it
+func (sn *StoryNest) configureSpeed(reader *bufio.Reader, speeds tts.Range) {
+	fmt.Println()
+	colours.Prompt.Printf("Enter speaking speed (%g-%g, current: %g): ", speeds.Min, speeds.Max, sn.speed)
+	speedInput, _ := reader.ReadString('\n')
+	speedInput = strings.TrimSpace(speedInput)
+	if speedInput == "" {
+		return
+	}
+
+	speed, err := strconv.ParseFloat(speedInput, 64)
+	if err != nil || !speeds.Contains(speed) {
+		colours.Error.Printf("❌ Speed must be between %g and %g\n", speeds.Min, speeds.Max)
+		return
+	}
+	if err := sn.engine().SetSpeed(speed); err != nil {
+		colours.Error.Printf("❌ Failed to set speed: %v\n", err)
+		return
+	}
+
+	sn.speed = speed
+	if err := saveSettings(map[string]interface{}{"tts.speed": speed}); err != nil {
+		colours.Error.Printf("❌ Could not save speed: %v\n", err)
+	}
+	colours.Success.Printf("✅ Speed set to: %.2f\n", speed)
+}
+
+// printVoiceChoices lists voices numbered from first, with their language and gender when known
+func printVoiceChoices(voices []tts.VoiceInfo, first int) {
+	for i, voice := range voices {
+		fmt.Printf("  %d. %s", first+i, voice.Name)
+		var details []string
+		if voice.LanguageCode != "" {
+			details = append(details, voice.LanguageCode)
+		}
+		if voice.Gender != "" {
+			details = append(details, voice.Gender)
+		}
+		if len(details) > 0 {
+			fmt.Printf(" (%s)", strings.Join(details, ", "))
+		}
+		fmt.Println()
+	}
+	fmt.Println()
+}
+
 // Show TTS Engine Status
 func (sn *StoryNest) ShowTTSStatus(cmd *cobra.Command, args []string) {
 	fmt.Println()
@@ -828,9 +1056,12 @@ func (sn *StoryNest) ShowTTSStatus(cmd *cobra.Command, args []string) {
 	// Current engine info
 	colours.Success.Printf("Engine: %s\n", sn.getCurrentEngineName())
 	colours.Info.Printf("Status: %s\n", sn.getTTSStatus())
+	if supported := sn.engine().Capabilities().Supported(); len(supported) > 0 {
+		colours.Info.Printf("Supports: %s\n", strings.Join(supported, ", "))
+	}
 
 	// Show voices if available
-	if voices, err := sn.Tts.GetAvailableVoices(); err == nil && len(voices) > 0 {
+	if voices, err := sn.engine().GetAvailableVoices(); err == nil && len(voices) > 0 {
 		colours.Info.Printf("Available Voices: %d\n", len(voices))
 		if len(voices) <= 10 {
 			for _, voice := range voices {
@@ -844,50 +1075,193 @@ func (sn *StoryNest) ShowTTSStatus(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	// Show cache stats for Chirp
-	if cacheable, ok := sn.Tts.(tts.CacheableEngine); ok {
-		fmt.Println()
-		colours.Info.Println("📁 Cache Statistics:")
-		if stats, err := cacheable.GetCacheStats(); err == nil {
-			fmt.Printf("  Directory: %s\n", stats["cache_directory"])
-			fmt.Printf("  Files: %d\n", stats["cached_files"])
-			fmt.Printf("  Size: %.2f MB\n", stats["total_size_mb"])
+	sn.showAudioCacheUsage("")
+}
+
+// TTSDoctor checks every engine, shows why each can or can't be used and the order they are tried in
+func (sn *StoryNest) TTSDoctor(cmd *cobra.Command, args []string) {
+	fmt.Println()
+	colours.Title.Println("🩺 TTS Engine Check 🩺")
+	fmt.Println()
+
+	engineConfig := sn.engineConfig(viper.GetString("tts.type"), viper.GetString("tts.voice"))
+	colours.Info.Println("🔍 Checking engines, this can take a few seconds...")
+	ready := map[tts.EngineType]bool{}
+	for _, status := range tts.Diagnose(sn.ctx, engineConfig) {
+		if status.Err != nil {
+			colours.Error.Printf("  ❌ %-14s", status.Type)
+			fmt.Printf(" %v\n", status.Err)
+			continue
 		}
+		ready[status.Type] = true
+		colours.Success.Printf("  ✅ %-14s", status.Type)
+		fmt.Println(" ready")
+	}
+
+	var chain []string
+	reading := tts.EngineTypeMock
+	for _, engineType := range tts.EngineChain(engineConfig.Type, viper.GetStringSlice("tts.engines")) {
+		name := engineType.String()
+		if !ready[engineType] {
+			name += " ✗"
+		} else if reading == tts.EngineTypeMock {
+			reading = engineType
+		}
+		chain = append(chain, name)
 	}
+	fmt.Println()
+	colours.Info.Printf("🔗 Tried in order: %s\n", strings.Join(chain, " → "))
+	colours.Success.Printf("🎤 Reading with: %s\n", reading)
+	colours.Info.Println("💡 Set the order with 'storynest settings set tts.engines googleclassic,piper,espeak'")
 }
 
 // Clear TTS Cache
 func (sn *StoryNest) ClearTTSCache(cmd *cobra.Command, args []string) {
-	if cacheable, ok := sn.Tts.(tts.CacheableEngine); ok {
-		colours.Info.Println("🧹 Clearing TTS cache...")
-		if err := cacheable.ClearCache(); err != nil {
-			colours.Error.Printf("❌ Failed to clear cache: %v\n", err)
-		} else {
-			colours.Success.Println("✅ TTS cache cleared successfully!")
-		}
+	colours.Info.Println("🧹 Clearing TTS cache...")
+	if err := sn.audioCache().Clear(); err != nil {
+		colours.Error.Printf("❌ Failed to clear cache: %v\n", err)
 	} else {
-		colours.Warning.Println("⚠️ Current TTS engine doesn't support caching")
+		colours.Success.Println("✅ TTS cache cleared successfully!")
+	}
+}
+
+// TestTTS speaks some sample text and waits until it has been read
+func (sn *StoryNest) TestTTS(cmd *cobra.Command, args []string) {
+	testText := "Hello! This is a test of the StoryNest text-to-speech system. How does it sound?"
+	if len(args) > 0 {
+		testText = strings.Join(args, " ")
+	}
+
+	colours.Info.Printf("🔊 Testing %s with: \"%s\"\n", sn.getCurrentEngineName(), testText)
+
+	events, unsubscribe := sn.engine().Subscribe()
+	defer unsubscribe()
+
+	if err := sn.engine().SpeakContext(sn.ctx, testText); err != nil {
+		colours.Error.Printf("❌ TTS test failed: %v\n", err)
+		return
+	}
+
+	var failed error
+	for {
+		select {
+		case <-sn.ctx.Done():
+			return
+		case ev := <-events:
+			switch ev.Type {
+			case tts.EventError:
+				failed = ev.Err
+			case tts.EventEngineSwitched:
+				colours.Warning.Println(engineSwitchNotice(ev))
+			case tts.EventFinished:
+				if failed != nil {
+					colours.Error.Printf("❌ TTS test failed: %v\n", failed)
+				} else {
+					colours.Success.Println("✅ TTS test finished")
+				}
+				return
+			}
+		}
 	}
 }
 
+// ListVoices shows the voices of the current engine, filtered by --lang and --gender
+func (sn *StoryNest) ListVoices(cmd *cobra.Command, args []string) {
+	lang, _ := cmd.Flags().GetString("lang")
+	gender, _ := cmd.Flags().GetString("gender")
+
+	voices, detailed, err := sn.voiceInfo()
+	if err != nil {
+		colours.Error.Printf("❌ Failed to get available voices: %v\n", err)
+		return
+	}
+	if !detailed && (lang != "" || gender != "") {
+		colours.Warning.Printf("⚠️ The %s engine doesn't describe its voices, so they can't be filtered\n", sn.getCurrentEngineName())
+		lang, gender = "", ""
+	}
+
+	var matching []tts.VoiceInfo
+	for _, voice := range voices {
+		if voiceMatches(voice, lang, gender) {
+			matching = append(matching, voice)
+		}
+	}
+
+	fmt.Println()
+	colours.Title.Printf("🗣️ %s voices (%d)\n", sn.getCurrentEngineName(), len(matching))
+	fmt.Println()
+
+	for _, voice := range matching {
+		colours.Prompt.Printf("  %s", voice.Name)
+		if detailed {
+			fmt.Printf("  %s", voice.LanguageCode)
+			if voice.Gender != "" {
+				colours.Author.Printf("  %s", voice.Gender)
+			}
+			if voice.Natural {
+				colours.Success.Print("  ✨ natural")
+			}
+		}
+		fmt.Println()
+		if voice.Description != "" {
+			colours.Info.Printf("    %s\n", voice.Description)
+		}
+	}
+
+	if len(matching) == 0 {
+		colours.Warning.Println("⚠️ No voices match")
+		return
+	}
+	fmt.Println()
+	colours.Info.Println("💡 Pick one with 'storynest settings set tts.voice <name>'")
+}
+
+// voiceInfo describes the current engine's voices, reporting whether the engine
+// gave details or only names
+func (sn *StoryNest) voiceInfo() ([]tts.VoiceInfo, bool, error) {
+	if enhanced, ok := sn.engine().(tts.EnhancedEngine); ok {
+		voices, err := enhanced.GetVoiceInfo()
+		return voices, true, err
+	}
+
+	names, err := sn.engine().GetAvailableVoices()
+	if err != nil {
+		return nil, false, err
+	}
+	voices := make([]tts.VoiceInfo, len(names))
+	for i, name := range names {
+		voices[i] = tts.VoiceInfo{Name: name}
+	}
+	return voices, false, nil
+}
+
+// voiceMatches checks a voice against the language (a prefix such as "en" matches "en-GB") and gender filters
+func voiceMatches(voice tts.VoiceInfo, lang, gender string) bool {
+	if lang != "" {
+		code := strings.ToLower(voice.LanguageCode)
+		lang = strings.ToLower(lang)
+		if code != lang && !strings.HasPrefix(code, lang+"-") {
+			return false
+		}
+	}
+	return gender == "" || strings.EqualFold(voice.Gender, gender)
+}
+
 func (sn *StoryNest) getCurrentEngineName() string {
-	// This would need to be implemented based on how you track the current engine
-	// For now, return a placeholder
-	return "Unknown"
+	_, engineType := sn.currentEngine()
+	return engineType.String()
 }
 
 func (sn *StoryNest) getTTSStatus() string {
-	if sn.Tts.IsPlaying() {
+	if sn.engine().IsPlaying() {
 		return "🔊 Playing"
 	}
-	if enhanced, ok := sn.Tts.(tts.EnhancedEngine); ok && enhanced.IsPaused() {
+	if enhanced, ok := sn.engine().(tts.EnhancedEngine); ok && enhanced.IsPaused() {
 		return "⏸️ Paused"
 	}
 	return "⏹️ Stopped"
 }
 
-// Add these commands to your main.go rootCmd setup:
-
 // AddTTSCommands adds TTS management commands to the CLI
 func (sn *StoryNest) AddTTSCommands(rootCmd *cobra.Command) {
 	// TTS parent command
@@ -917,30 +1291,36 @@ func (sn *StoryNest) AddTTSCommands(rootCmd *cobra.Command) {
 	clearCacheCmd := &cobra.Command{
 		Use:   "clear-cache",
 		Short: "🧹 Clear TTS cache",
-		Long:  "Clear cached audio files (applies to Chirp TTS)",
+		Long:  "Clear the cached audio of every book and engine",
 		Run:   sn.ClearTTSCache,
 	}
 
 	// Test TTS subcommand
 	testCmd := &cobra.Command{
-		Use:   "test",
+		Use:   "test [text]",
 		Short: "🔊 Test TTS with sample text",
-		Long:  "Test the current TTS engine with sample text",
-		Run: func(cmd *cobra.Command, args []string) {
-			testText := "Hello! This is a test of the StoryNest text-to-speech system. How does it sound?"
-			if len(args) > 0 {
-				testText = strings.Join(args, " ")
-			}
+		Long:  "Test the current TTS engine with sample text and wait for it to finish",
+		Run:   sn.TestTTS,
+	}
 
-			colours.Info.Printf("🔊 Testing TTS with: \"%s\"\n", testText)
-			if err := sn.Tts.Speak(testText); err != nil {
-				colours.Error.Printf("❌ TTS test failed: %v\n", err)
-			} else {
-				colours.Success.Println("✅ TTS test started successfully!")
-			}
-		},
+	// Voices subcommand
+	voicesCmd := &cobra.Command{
+		Use:   "voices",
+		Short: "🗣️ List voices",
+		Long:  "List the voices the current TTS engine offers, optionally filtered by language and gender",
+		Run:   sn.ListVoices,
+	}
+	voicesCmd.Flags().String("lang", "", "Only voices for this language, e.g. en or en-GB")
+	voicesCmd.Flags().String("gender", "", "Only voices of this gender: female, male or neutral")
+
+	// Doctor subcommand
+	doctorCmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "🩺 Check which TTS engines work",
+		Long:  "Check every TTS engine and explain why each one can or can't be used here",
+		Run:   sn.TTSDoctor,
 	}
 
-	ttsCmd.AddCommand(configureCmd, statusCmd, clearCacheCmd, testCmd)
+	ttsCmd.AddCommand(configureCmd, statusCmd, clearCacheCmd, testCmd, voicesCmd, doctorCmd)
 	rootCmd.AddCommand(ttsCmd)
 }
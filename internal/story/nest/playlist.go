@@ -0,0 +1,232 @@
+package nest
+
+import (
+	"fmt"
+	"storynest/internal/cli/scheme/colours"
+	"storynest/internal/config"
+	"storynest/internal/domain/playlist"
+	"storynest/internal/domain/story"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func (sn *StoryNest) playlistStore() *playlist.Store {
+	return playlist.NewStore(config.Dir())
+}
+
+// CreatePlaylist creates a named playlist, optionally seeded with stories
+func (sn *StoryNest) CreatePlaylist(cmd *cobra.Command, args []string) {
+	name := args[0]
+	storyIDs := args[1:]
+
+	for _, storyID := range storyIDs {
+		if sn.findStoryByID(storyID) == nil {
+			colours.Error.Printf("❌ Story with ID '%s' not found!\n", storyID)
+			return
+		}
+	}
+
+	p, err := sn.playlistStore().Create(name, storyIDs...)
+	if err != nil {
+		colours.Error.Printf("❌ Failed to create playlist: %v\n", err)
+		return
+	}
+
+	colours.Success.Printf("✅ Created playlist '%s' with %d stories\n", p.Name, len(p.Items))
+}
+
+// AddToPlaylist appends stories to an existing playlist
+func (sn *StoryNest) AddToPlaylist(cmd *cobra.Command, args []string) {
+	name := args[0]
+	storyIDs := args[1:]
+
+	store := sn.playlistStore()
+	p, err := store.Get(name)
+	if err != nil {
+		colours.Error.Printf("❌ %v\n", err)
+		return
+	}
+
+	for _, storyID := range storyIDs {
+		if sn.findStoryByID(storyID) == nil {
+			colours.Error.Printf("❌ Story with ID '%s' not found!\n", storyID)
+			return
+		}
+	}
+
+	p.Add(storyIDs...)
+	if err := store.Save(p); err != nil {
+		colours.Error.Printf("❌ Failed to save playlist: %v\n", err)
+		return
+	}
+
+	colours.Success.Printf("✅ Added %d stories to '%s' (%d total)\n", len(storyIDs), p.Name, len(p.Items))
+}
+
+// ListPlaylists shows every saved playlist with its progress
+func (sn *StoryNest) ListPlaylists(cmd *cobra.Command, args []string) {
+	fmt.Println()
+	colours.Title.Println("🎶 Bedtime Playlists 🎶")
+	fmt.Println()
+
+	playlists, err := sn.playlistStore().List()
+	if err != nil {
+		colours.Error.Printf("❌ Failed to load playlists: %v\n", err)
+		return
+	}
+
+	if len(playlists) == 0 {
+		colours.Warning.Println("🔍 No playlists yet.")
+		colours.Info.Println("💡 Create one with: storynest playlist create <name> [story-ids...]")
+		return
+	}
+
+	for _, p := range playlists {
+		colours.Info.Printf("📜 %s", p.Name)
+		fmt.Printf(" (%d stories)\n", len(p.Items))
+
+		for i, item := range p.Items {
+			title := item.StoryID
+			if s := sn.findStoryByID(item.StoryID); s != nil {
+				title = s.Title
+			}
+			fmt.Printf("  %d. %s %s\n", i+1, statusIcon(item.Status), title)
+		}
+		fmt.Println()
+	}
+}
+
+// DeletePlaylist removes a playlist
+func (sn *StoryNest) DeletePlaylist(cmd *cobra.Command, args []string) {
+	if err := sn.playlistStore().Delete(args[0]); err != nil {
+		colours.Error.Printf("❌ %v\n", err)
+		return
+	}
+	colours.Success.Printf("✅ Deleted playlist '%s'\n", args[0])
+}
+
+// PlayPlaylist plays a playlist from the first story that hasn't finished yet
+func (sn *StoryNest) PlayPlaylist(cmd *cobra.Command, args []string) {
+	restart, _ := cmd.Flags().GetBool("restart")
+
+	sn.applyVoiceFlag(cmd)
+
+	sn.applySleepFlag(cmd)
+	sn.applyAmbientFlag(cmd)
+	sn.applyFollowFlag(cmd)
+
+	store := sn.playlistStore()
+	p, err := store.Get(args[0])
+	if err != nil {
+		colours.Error.Printf("❌ %v\n", err)
+		return
+	}
+
+	if len(p.Items) == 0 {
+		colours.Warning.Printf("⚠️ Playlist '%s' is empty\n", p.Name)
+		return
+	}
+
+	stories := make([]story.Item, 0, len(p.Items))
+	for _, storyID := range p.StoryIDs() {
+		s := sn.findStoryByID(storyID)
+		if s == nil {
+			colours.Error.Printf("❌ Story with ID '%s' not found!\n", storyID)
+			return
+		}
+		stories = append(stories, *s)
+	}
+
+	start := p.NextUnfinished()
+	if restart || start < 0 {
+		p.Reset()
+		start = 0
+	}
+
+	if start > 0 {
+		colours.Info.Printf("⏩ Resuming '%s' at story %d\n", p.Name, start+1)
+	}
+
+	sn.playQueue(stories, start, pauseBetweenStories(cmd), func(index int, status playlist.ItemStatus) {
+		p.SetStatus(index, status)
+		if err := store.Save(p); err != nil {
+			colours.Warning.Printf("⚠️ Could not save playlist progress: %v\n", err)
+		}
+	})
+}
+
+func statusIcon(status playlist.ItemStatus) string {
+	switch status {
+	case playlist.StatusFinished:
+		return "✅"
+	case playlist.StatusSkipped:
+		return "⏭️"
+	case playlist.StatusPlaying:
+		return "▶️"
+	default:
+		return "⬜"
+	}
+}
+
+// AddPlaylistCommands adds playlist management commands to the CLI
+func (sn *StoryNest) AddPlaylistCommands(rootCmd *cobra.Command) {
+	// Playlist parent command
+	playlistCmd := &cobra.Command{
+		Use:   "playlist",
+		Short: "🎶 Manage bedtime playlists",
+		Long:  "Create named lists of stories that play one after another",
+	}
+
+	// Create subcommand
+	createCmd := &cobra.Command{
+		Use:   "create <name> [story-id...]",
+		Short: "➕ Create a playlist",
+		Long:  "Create a new playlist, optionally with some stories already in it",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   sn.CreatePlaylist,
+	}
+
+	// Add subcommand
+	addCmd := &cobra.Command{
+		Use:   "add <name> <story-id...>",
+		Short: "📥 Add stories to a playlist",
+		Long:  "Append one or more stories to the end of a playlist",
+		Args:  cobra.MinimumNArgs(2),
+		Run:   sn.AddToPlaylist,
+	}
+
+	// List subcommand
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "📋 List playlists",
+		Long:  "Show every playlist and how far each one has been played",
+		Run:   sn.ListPlaylists,
+	}
+
+	// Delete subcommand
+	deleteCmd := &cobra.Command{
+		Use:   "delete <name>",
+		Short: "🗑️ Delete a playlist",
+		Long:  "Remove a playlist (the stories themselves are kept)",
+		Args:  cobra.ExactArgs(1),
+		Run:   sn.DeletePlaylist,
+	}
+
+	// Play subcommand
+	playCmd := &cobra.Command{
+		Use:   "play <name>",
+		Short: "▶️ Play a playlist",
+		Long:  "Read every story in a playlist, picking up where you left off",
+		Args:  cobra.ExactArgs(1),
+		Run:   sn.PlayPlaylist,
+	}
+	playCmd.Flags().Bool("restart", false, "Start again from the first story")
+	playCmd.Flags().Duration("pause", 3*time.Second, "Pause between stories")
+	playCmd.Flags().Duration("sleep", 0, "Fade out and stop after this long (e.g. 20m)")
+	playCmd.Flags().String("ambient", "", "Background sound: an .mp3/.wav file or white, pink, brown noise")
+	playCmd.Flags().BoolP("follow", "f", false, "Show the story text and highlight the words as they are read")
+
+	playlistCmd.AddCommand(createCmd, addCmd, listCmd, deleteCmd, playCmd)
+	rootCmd.AddCommand(playlistCmd)
+}
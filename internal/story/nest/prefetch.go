@@ -0,0 +1,272 @@
+package nest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"storynest/internal/cli/scheme/colours"
+	"storynest/internal/domain/story"
+	"storynest/internal/story/tts"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// prefetchWorkers is how many stories are synthesized at once by default
+const prefetchWorkers = 3
+
+// prefetchProgress tracks a prefetch run across its workers
+type prefetchProgress struct {
+	mu       sync.Mutex
+	started  time.Time
+	total    int // characters of text in every story
+	done     map[string]float64
+	sizes    map[string]int
+	finished int
+	failed   int
+	stories  int
+	current  string
+	live     bool // redraw a progress line rather than printing as stories finish
+}
+
+func newPrefetchProgress(stories []story.Item) *prefetchProgress {
+	p := &prefetchProgress{
+		started: time.Now(),
+		done:    make(map[string]float64),
+		sizes:   make(map[string]int),
+		stories: len(stories),
+		live:    term.IsTerminal(int(os.Stdout.Fd())),
+	}
+	for _, s := range stories {
+		p.sizes[s.ID] = len(s.Content)
+		p.total += len(s.Content)
+	}
+	return p
+}
+
+// update records how far through its chunks a story is
+func (p *prefetchProgress) update(item story.Item, done, total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done[item.ID] = float64(done) / float64(total)
+	p.current = item.Title
+	p.draw()
+}
+
+// report prints the outcome of one story above the progress line
+func (p *prefetchProgress) report(item story.Item, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.finished++
+	if p.live {
+		clearStatus()
+	}
+	if err != nil {
+		p.failed++
+		colours.Error.Printf("❌ %s: %v\n", item.Title, err)
+	} else {
+		p.done[item.ID] = 1
+		colours.Success.Printf("✅ %s (%d/%d)\n", item.Title, p.finished, p.stories)
+	}
+	p.draw()
+}
+
+// fraction is how much of the text has audio, weighting stories by their length
+func (p *prefetchProgress) fraction() float64 {
+	if p.total == 0 {
+		return 1
+	}
+	var done float64
+	for id, f := range p.done {
+		done += f * float64(p.sizes[id])
+	}
+	return done / float64(p.total)
+}
+
+func (p *prefetchProgress) draw() {
+	if !p.live {
+		return
+	}
+
+	fraction := p.fraction()
+	line := fmt.Sprintf("⏳ %d/%d stories · %d%%", p.finished, p.stories, int(fraction*100))
+	if elapsed := time.Since(p.started); fraction > 0.02 && fraction < 1 {
+		eta := time.Duration(float64(elapsed) / fraction * (1 - fraction))
+		line += " · about " + formatClock(eta) + " left"
+	}
+	if p.current != "" {
+		line += " · " + p.current
+	}
+
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 8 {
+		line = truncateWidth(line, width-6)
+	}
+	fmt.Print("\r\033[K" + colours.Info.Sprint(line))
+}
+
+// Prefetch synthesizes stories into the audio cache so they play without the network
+func (sn *StoryNest) Prefetch(cmd *cobra.Command, args []string) {
+	playlistName, _ := cmd.Flags().GetString("playlist")
+	favourites, _ := cmd.Flags().GetBool("favourites")
+	workers, _ := cmd.Flags().GetInt("workers")
+
+	prefetcher, ok := sn.engine().(tts.PrefetchEngine)
+	if !ok {
+		colours.Warning.Printf("⚠️ The %s engine doesn't cache audio, so there's nothing to prefetch\n", sn.getCurrentEngineName())
+		return
+	}
+	cache := sn.audioCache()
+	if !cache.Enabled() {
+		colours.Warning.Println("⚠️ Audio caching is off, turn it on with 'storynest settings set tts.cache_enabled true'")
+		return
+	}
+
+	ids, err := sn.prefetchIDs(args, playlistName, favourites)
+	if err != nil {
+		colours.Error.Printf("❌ %v\n", err)
+		return
+	}
+	if len(ids) == 0 {
+		colours.Error.Println("❌ Name some stories, a --playlist or --favourites to prefetch")
+		return
+	}
+
+	var stories []story.Item
+	for _, id := range ids {
+		s := sn.findStoryByID(id)
+		if s == nil {
+			colours.Warning.Printf("⚠️ Story with ID '%s' not found, skipping it\n", id)
+			continue
+		}
+		stories = append(stories, *s)
+	}
+	if len(stories) == 0 {
+		return
+	}
+
+	if workers < 1 {
+		workers = 1
+	}
+	colours.Info.Printf("📥 Prefetching %d stories with %s, %d at a time...\n", len(stories), sn.getCurrentEngineName(), workers)
+	fmt.Println("💡 Press Ctrl+C to stop, and run the same command again to carry on")
+
+	progress := newPrefetchProgress(stories)
+	jobs := make(chan story.Item)
+	var filled atomic.Bool // a story stopped part way because the cache filled up
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range jobs {
+				err := prefetcher.Prefetch(sn.ctx, extractProviderFromStoryID(item.ID), extractBookIDFromStoryID(item.ID), item.Content, func(done, total int) {
+					progress.update(item, done, total)
+				})
+				if sn.ctx.Err() != nil {
+					return
+				}
+				if errors.Is(err, tts.ErrCacheFull) {
+					filled.Store(true)
+					continue
+				}
+				progress.report(item, err)
+			}
+		}()
+	}
+
+	full := false
+queue:
+	for _, item := range stories {
+		if full = filled.Load() || cacheFull(cache); full {
+			break
+		}
+		select {
+		case jobs <- item:
+		case <-sn.ctx.Done():
+			break queue
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	full = full || filled.Load()
+
+	if progress.live {
+		clearStatus()
+	}
+	switch {
+	case sn.ctx.Err() != nil:
+		colours.Warning.Println("⏸️ Prefetch stopped, run it again to carry on where it left off")
+	case full:
+		colours.Warning.Printf("⚠️ The audio cache is full (%s), so some stories weren't prefetched\n", formatSize(cache.Limit()))
+		colours.Info.Println("💡 Make room with 'storynest cache prune' or raise tts.cache_max_size_mb")
+	case progress.failed > 0:
+		colours.Warning.Printf("⚠️ Prefetched %d stories, %d failed. Run it again to retry them\n", progress.finished-progress.failed, progress.failed)
+	default:
+		colours.Success.Printf("🎉 Prefetched %d stories in %s\n", progress.finished, formatClock(time.Since(progress.started)))
+	}
+}
+
+// prefetchIDs gathers the stories to prefetch from the arguments, a playlist and the pinned favourites
+func (sn *StoryNest) prefetchIDs(args []string, playlistName string, favourites bool) ([]string, error) {
+	ids := append([]string{}, args...)
+
+	if playlistName != "" {
+		p, err := sn.playlistStore().Get(playlistName)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, p.StoryIDs()...)
+	}
+
+	if favourites {
+		pinned, err := sn.audioCache().PinnedBooks()
+		if err != nil {
+			return nil, err
+		}
+		if len(pinned) == 0 {
+			colours.Warning.Println("⚠️ No favourites yet, pin some with 'storynest cache pin <story-id>'")
+		}
+		for _, book := range pinned {
+			ids = append(ids, storyIDFor(book.Provider, book.BookID))
+		}
+	}
+
+	// Each story only once, keeping the order they were asked for in
+	seen := make(map[string]bool)
+	unique := ids[:0]
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique, nil
+}
+
+// cacheFull reports whether the cache has reached its size limit
+func cacheFull(cache tts.CacheManager) bool {
+	if cache.Limit() <= 0 {
+		return false
+	}
+	books, err := cache.Books()
+	return err == nil && totalSize(books) >= cache.Limit()
+}
+
+// AddPrefetchCommands adds the prefetch command to the CLI
+func (sn *StoryNest) AddPrefetchCommands(rootCmd *cobra.Command) {
+	prefetchCmd := &cobra.Command{
+		Use:   "prefetch [story-id...]",
+		Short: "📥 Synthesize stories ahead of time for offline playback",
+		Long:  "Synthesize and cache the audio for stories in the background, so they play later without the network",
+		Run:   sn.Prefetch,
+	}
+	prefetchCmd.Flags().String("playlist", "", "Prefetch every story in this playlist")
+	prefetchCmd.Flags().Bool("favourites", false, "Prefetch every pinned story (see 'storynest cache pin')")
+	prefetchCmd.Flags().Int("workers", prefetchWorkers, "How many stories to synthesize at once")
+
+	rootCmd.AddCommand(prefetchCmd)
+}
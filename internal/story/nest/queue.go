@@ -0,0 +1,106 @@
+package nest
+
+import (
+	"fmt"
+	"storynest/internal/cli/scheme/colours"
+	"storynest/internal/domain/playlist"
+	"storynest/internal/domain/story"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// progressFunc is told whenever a queued story changes state
+type progressFunc func(index int, status playlist.ItemStatus)
+
+// playQueue reads stories one after another starting at index start.
+// The listener can move between entries with next/previous while a story plays.
+func (sn *StoryNest) playQueue(stories []story.Item, start int, pause time.Duration, onProgress progressFunc) {
+	if onProgress == nil {
+		onProgress = func(int, playlist.ItemStatus) {}
+	}
+
+	fmt.Println()
+	colours.Success.Printf("🎵 Playing %d stories... 🎵\n", len(stories)-start)
+	fmt.Println("💡 Press Ctrl+C to stop anytime")
+
+	sn.armSleepTimer()
+	sn.startAmbient()
+	defer sn.lingerAmbient()
+
+	i := start
+	for i >= 0 && i < len(stories) {
+		fmt.Println()
+		colours.Info.Printf("🎶 Story %d of %d\n", i+1, len(stories))
+		sn.showStoryHeader(stories[i])
+
+		offset := sn.bookmarkOffset(stories[i])
+		if offset > 0 {
+			colours.Info.Printf("📑 Carrying on from %d%%\n", offset*100/len(stories[i].Content))
+		}
+
+		onProgress(i, playlist.StatusPlaying)
+
+		switch sn.playStory(stories[i], offset, true) {
+		case actionFinished:
+			onProgress(i, playlist.StatusFinished)
+			i++
+			if i < len(stories) && !sn.waitBetweenStories(pause) {
+				return
+			}
+		case actionNext:
+			onProgress(i, playlist.StatusSkipped)
+			i++
+		case actionPrevious:
+			onProgress(i, playlist.StatusPending)
+			if i > 0 {
+				i--
+			}
+		case actionStop:
+			onProgress(i, playlist.StatusPending)
+			return
+		case actionSleep:
+			onProgress(i, playlist.StatusPending)
+			colours.Prompt.Println("😴 Sleep tight! 🌙")
+			return
+		}
+	}
+
+	fmt.Println()
+	colours.Success.Println("✅ All stories finished! 🌟")
+	colours.Prompt.Println("😴 Sleep tight! 🌙")
+}
+
+// waitBetweenStories sleeps for the configured gap, returning false if the app is
+// shutting down or the sleep timer is (nearly) up
+func (sn *StoryNest) waitBetweenStories(pause time.Duration) bool {
+	if sn.sleepNearlyUp() {
+		colours.Prompt.Println("😴 The sleep timer is nearly up, no more stories tonight. Sleep tight! 🌙")
+		return false
+	}
+
+	if pause <= 0 {
+		return true
+	}
+
+	colours.Info.Printf("🌙 Next story in %s...\n", pause)
+	select {
+	case <-sn.ctx.Done():
+		return false
+	case <-sn.sleepExpired():
+		colours.Prompt.Println("😴 Sleep tight! 🌙")
+		return false
+	case <-time.After(pause):
+		return true
+	}
+}
+
+// pauseBetweenStories returns the --pause flag if given, otherwise the configured default
+func pauseBetweenStories(cmd *cobra.Command) time.Duration {
+	if flag := cmd.Flags().Lookup("pause"); flag != nil && flag.Changed {
+		pause, _ := cmd.Flags().GetDuration("pause")
+		return pause
+	}
+	return viper.GetDuration("playback.pause_between")
+}
@@ -0,0 +1,156 @@
+package nest
+
+import (
+	"sort"
+	"storynest/internal/cli/scheme/colours"
+	"storynest/internal/domain/story"
+	"storynest/internal/story/tts"
+)
+
+// seekUnit is how far a single seek moves
+type seekUnit int
+
+const (
+	seekSentence seekUnit = iota
+	seekParagraph
+	seekChapter
+)
+
+func (u seekUnit) String() string {
+	switch u {
+	case seekParagraph:
+		return "paragraph"
+	case seekChapter:
+		return "chapter"
+	default:
+		return "sentence"
+	}
+}
+
+// parseSeek understands "<", "<<", "<<<" (and ">" for forward) as well as
+// "back"/"forward" followed by an optional unit
+func parseSeek(command, arg string) (seekUnit, int, bool) {
+	switch command {
+	case "<", ">", "<<", ">>", "<<<", ">>>":
+		dir := 1
+		if command[0] == '<' {
+			dir = -1
+		}
+		return seekUnit(len(command) - 1), dir, true
+	case "back", "forward", "fwd":
+		dir := 1
+		if command == "back" {
+			dir = -1
+		}
+		switch arg {
+		case "p", "para", "paragraph":
+			return seekParagraph, dir, true
+		case "c", "chapter":
+			return seekChapter, dir, true
+		default:
+			return seekSentence, dir, true
+		}
+	}
+	return 0, 0, false
+}
+
+// storyBoundaries returns the offsets where each sentence, paragraph or chapter of the story starts
+func storyBoundaries(item story.Item, unit seekUnit) []int {
+	switch unit {
+	case seekParagraph:
+		return tts.ParagraphStarts(item.Content)
+	case seekChapter:
+		starts := []int{0}
+		for _, ch := range item.Chapters() {
+			if ch.Offset > 0 {
+				starts = append(starts, ch.Offset)
+			}
+		}
+		return starts
+	default:
+		return tts.SentenceStarts(item.Content)
+	}
+}
+
+// seekTarget works out where a seek lands. Going back from part way through a
+// sentence (or paragraph, chapter) returns to its start; from its very start, to the one before.
+func seekTarget(boundaries []int, current, dir int) (int, bool) {
+	// k is the last boundary at or before current
+	k := sort.SearchInts(boundaries, current+1) - 1
+
+	if dir < 0 {
+		if k >= 0 && boundaries[k] < current {
+			return boundaries[k], true
+		}
+		if k-1 >= 0 {
+			return boundaries[k-1], true
+		}
+		if current > 0 {
+			return 0, true
+		}
+		return 0, false
+	}
+
+	if k+1 < len(boundaries) {
+		return boundaries[k+1], true
+	}
+	return 0, false
+}
+
+// currentStoryOffset returns where playback is in the story, given the offset it was started from
+func (sn *StoryNest) currentStoryOffset(base int) int {
+	if follower, ok := sn.engine().(tts.FollowEngine); ok {
+		if start, _, ok := follower.Spoken(); ok {
+			return base + start
+		}
+	}
+	if seeker, ok := sn.engine().(tts.SeekableEngine); ok {
+		return base + seeker.Position().Offset
+	}
+	if progress, ok := sn.engine().(tts.ProgressEngine); ok {
+		return base + progress.Offset()
+	}
+	return base
+}
+
+// seek moves playback by one unit. If the engine can't get there itself (it can't seek,
+// or the target is before the text it was given) it returns the offset to restart from.
+func (sn *StoryNest) seek(item story.Item, base int, unit seekUnit, dir int) (int, bool) {
+	current := sn.currentStoryOffset(base)
+	target, ok := seekTarget(storyBoundaries(item, unit), current, dir)
+	if !ok {
+		if dir < 0 {
+			colours.Info.Println("⏮️  Already at the beginning")
+		} else {
+			colours.Info.Printf("⏭️  No next %s\n", unit)
+		}
+		return 0, false
+	}
+
+	arrow := "⏩"
+	if dir < 0 {
+		arrow = "⏪"
+	}
+	colours.Info.Printf("%s  %s\n", arrow, seekMessage(unit, dir))
+
+	if sn.seekEngine(base, target) {
+		return 0, false
+	}
+	return target, true
+}
+
+// seekEngine asks the engine to move to target in the story, reporting whether it could
+func (sn *StoryNest) seekEngine(base, target int) bool {
+	seeker, ok := sn.engine().(tts.SeekableEngine)
+	if !ok || target < base {
+		return false
+	}
+	return seeker.Seek(target-base) == nil
+}
+
+func seekMessage(unit seekUnit, dir int) string {
+	if dir < 0 {
+		return "Back a " + unit.String()
+	}
+	return "On to the next " + unit.String()
+}
@@ -0,0 +1,379 @@
+package nest
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"storynest/internal/cli/scheme/colours"
+	"storynest/internal/config"
+	"storynest/internal/story/tts"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// settingKind is the type of value a setting holds
+type settingKind int
+
+const (
+	kindString settingKind = iota
+	kindNumber
+	kindInteger
+	kindBool
+	kindDuration
+	kindList
+)
+
+// setting is a value that can be changed with 'storynest settings set'
+type setting struct {
+	key         string
+	kind        settingKind
+	description string
+	// check validates a parsed value and applies it to the running app if it needs to
+	check func(sn *StoryNest, value interface{}) error
+}
+
+var settings = []setting{
+	{key: "tts.type", kind: kindString, description: "TTS engine: auto or one of the engines available here", check: checkEngineType},
+	{key: "tts.engines", kind: kindList, description: "Engines to fall back on, in order, e.g. googleclassic,piper,espeak (empty for every one set up)", check: checkEngineChain},
+	{key: "tts.voice", kind: kindString, description: "Voice to read with, or 'default'", check: checkVoice},
+	{key: "tts.speed", kind: kindNumber, description: "Reading speed, 1.0 is normal", check: checkSpeed},
+	{key: "tts.volume", kind: kindNumber, description: "Narration volume, 1.0 is normal and up to 2.0 boosts it", check: checkVolume},
+	{key: "tts.cache_enabled", kind: kindBool, description: "Keep synthesized audio so stories replay without the network"},
+	{key: "tts.cache_max_size_mb", kind: kindInteger, description: "Largest the audio cache may grow, in MB (0 for no limit)", check: checkNotNegative},
+	{key: "tts.piper.path", kind: kindString, description: "Piper binary, or empty to find it on the PATH"},
+	{key: "tts.piper.models_dir", kind: kindString, description: "Folder of Piper .onnx voice models and their .onnx.json files"},
+	{key: "tts.http.base_url", kind: kindString, description: "OpenAI-compatible speech server, up to and including /v1"},
+	{key: "tts.http.model", kind: kindString, description: "Model the speech server should use"},
+	{key: "playback.pause_between", kind: kindDuration, description: "Quiet gap between queued stories", check: checkNotNegative},
+	{key: "playback.sleep_timer", kind: kindDuration, description: "Sleep timer length when none is given", check: checkPositive},
+	{key: "ambient.source", kind: kindString, description: "Background sound: a file, white, pink, brown or off", check: checkAmbientSource},
+	{key: "ambient.volume", kind: kindNumber, description: "Background volume relative to the narration (0-1)", check: checkFraction},
+	{key: "ambient.duck", kind: kindNumber, description: "Share of the background volume kept while speaking (0-1)", check: checkFraction},
+	{key: "ambient.linger", kind: kindDuration, description: "Keep the background playing this long after the stories end", check: checkNotNegative},
+}
+
+// findSetting looks up a setting by its key
+func findSetting(key string) (setting, bool) {
+	for _, s := range settings {
+		if s.key == strings.ToLower(key) {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+// parse turns the text given on the command line into the setting's type
+func (s setting) parse(value string) (interface{}, error) {
+	switch s.kind {
+	case kindNumber:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s needs a number, not '%s'", s.key, value)
+		}
+		return f, nil
+	case kindInteger:
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s needs a whole number, not '%s'", s.key, value)
+		}
+		return i, nil
+	case kindBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s needs true or false, not '%s'", s.key, value)
+		}
+		return b, nil
+	case kindDuration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s needs a duration such as 30s or 20m, not '%s'", s.key, value)
+		}
+		return d, nil
+	case kindList:
+		return strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }), nil
+	default:
+		return value, nil
+	}
+}
+
+// current formats the setting's value for display
+func (s setting) current() string {
+	switch s.kind {
+	case kindDuration:
+		return viper.GetDuration(s.key).String()
+	case kindNumber:
+		return strconv.FormatFloat(viper.GetFloat64(s.key), 'g', -1, 64)
+	case kindList:
+		return strings.Join(viper.GetStringSlice(s.key), ",")
+	default:
+		return fmt.Sprint(viper.Get(s.key))
+	}
+}
+
+func checkEngineType(sn *StoryNest, value interface{}) error {
+	engine := value.(string)
+	if engine == tts.EngineTypeAuto.String() {
+		return nil
+	}
+
+	var names []string
+	for _, available := range tts.GetAvailableEngines() {
+		if available.String() == engine {
+			return nil
+		}
+		names = append(names, available.String())
+	}
+	return fmt.Errorf("engine '%s' isn't available here (try auto, %s)", engine, strings.Join(names, ", "))
+}
+
+func checkEngineChain(sn *StoryNest, value interface{}) error {
+	var names []string
+	for _, engineType := range tts.AllEngineTypes() {
+		names = append(names, engineType.String())
+	}
+	for _, engine := range value.([]string) {
+		if !slices.Contains(names, engine) {
+			return fmt.Errorf("there is no engine called '%s' (choose from %s)", engine, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+func checkVoice(sn *StoryNest, value interface{}) error {
+	voice := value.(string)
+	if voice == "default" {
+		return nil
+	}
+
+	if voices, err := sn.engine().GetAvailableVoices(); err == nil && len(voices) > 0 && !slices.Contains(voices, voice) {
+		return fmt.Errorf("the current engine has no voice called '%s'", voice)
+	}
+	return sn.engine().SetVoice(voice)
+}
+
+func checkSpeed(sn *StoryNest, value interface{}) error {
+	speed := value.(float64)
+	if r := sn.engine().Capabilities().Speed; !r.Contains(speed) {
+		if r.Min == r.Max {
+			return fmt.Errorf("the current voice only reads at speed %g", r.Min)
+		}
+		return fmt.Errorf("the current engine reads at speeds from %g to %g", r.Min, r.Max)
+	}
+	if err := sn.engine().SetSpeed(speed); err != nil {
+		return err
+	}
+	sn.speed = speed
+	return nil
+}
+
+func checkVolume(sn *StoryNest, value interface{}) error {
+	volume := value.(float64)
+	if r := sn.engine().Capabilities().Volume; !r.Contains(volume) {
+		return fmt.Errorf("the current engine plays at volumes from %g to %g", r.Min, r.Max)
+	}
+	if err := sn.engine().SetVolume(volume); err != nil {
+		return err
+	}
+	sn.volume = volume
+	return nil
+}
+
+func checkFraction(sn *StoryNest, value interface{}) error {
+	if f := value.(float64); f < 0 || f > 1 {
+		return fmt.Errorf("must be between 0 and 1")
+	}
+	return nil
+}
+
+func checkNotNegative(sn *StoryNest, value interface{}) error {
+	switch v := value.(type) {
+	case int:
+		if v < 0 {
+			return fmt.Errorf("can't be negative")
+		}
+	case time.Duration:
+		if v < 0 {
+			return fmt.Errorf("can't be negative")
+		}
+	}
+	return nil
+}
+
+func checkPositive(sn *StoryNest, value interface{}) error {
+	if d := value.(time.Duration); d <= 0 {
+		return fmt.Errorf("must be longer than zero")
+	}
+	return nil
+}
+
+func checkAmbientSource(sn *StoryNest, value interface{}) error {
+	switch source := value.(string); source {
+	case "", "off", "white", "pink", "brown":
+		return nil
+	default:
+		if _, err := os.Stat(source); err != nil {
+			return fmt.Errorf("can't find sound file '%s'", source)
+		}
+		return nil
+	}
+}
+
+// ListSettings shows every setting with its current value
+func (sn *StoryNest) ListSettings(cmd *cobra.Command, args []string) {
+	fmt.Println()
+	colours.Title.Println("⚙️ StoryNest Settings ⚙️")
+	fmt.Println()
+
+	for _, s := range settings {
+		colours.Prompt.Printf("  %-24s", s.key)
+		fmt.Printf(" %-12s", s.current())
+		if !config.IsSet(s.key) {
+			colours.Info.Print(" (default)")
+		}
+		fmt.Println()
+		fmt.Printf("  %-24s %s\n", "", s.description)
+	}
+
+	fmt.Println()
+	colours.Info.Printf("💡 Change one with 'storynest settings set <key> <value>'. Saved in %s\n", config.Path())
+}
+
+// GetSetting prints the value of one setting
+func (sn *StoryNest) GetSetting(cmd *cobra.Command, args []string) {
+	s, ok := findSetting(args[0])
+	if !ok {
+		unknownSetting(args[0])
+		return
+	}
+	fmt.Println(s.current())
+}
+
+// SetSetting validates a new value, saves it to the config file and applies it
+func (sn *StoryNest) SetSetting(cmd *cobra.Command, args []string) {
+	s, ok := findSetting(args[0])
+	if !ok {
+		unknownSetting(args[0])
+		return
+	}
+
+	value, err := s.parse(strings.Join(args[1:], " "))
+	if err != nil {
+		colours.Error.Printf("❌ %v\n", err)
+		return
+	}
+	if s.check != nil {
+		if err := s.check(sn, value); err != nil {
+			colours.Error.Printf("❌ Invalid %s: %v\n", s.key, err)
+			return
+		}
+	}
+
+	// Durations are saved as text so the file stays readable
+	if d, ok := value.(time.Duration); ok {
+		value = d.String()
+	}
+	if err := config.Save(s.key, value); err != nil {
+		colours.Error.Printf("❌ Could not save setting: %v\n", err)
+		return
+	}
+
+	colours.Success.Printf("✅ %s set to %s\n", s.key, s.current())
+	if s.key == "tts.type" || s.key == "tts.engines" {
+		colours.Info.Println("💡 The new engine is used from the next command")
+	}
+}
+
+// ResetSettings puts settings back to their defaults, or all of them if none are named
+func (sn *StoryNest) ResetSettings(cmd *cobra.Command, args []string) {
+	var keys []string
+	for _, arg := range args {
+		s, ok := findSetting(arg)
+		if !ok {
+			unknownSetting(arg)
+			return
+		}
+		keys = append(keys, s.key)
+	}
+	if len(keys) == 0 {
+		for _, s := range settings {
+			keys = append(keys, s.key)
+		}
+	}
+
+	if err := config.Reset(keys...); err != nil {
+		colours.Error.Printf("❌ Could not reset settings: %v\n", err)
+		return
+	}
+
+	if len(args) == 0 {
+		colours.Success.Println("✅ All settings are back to their defaults")
+		return
+	}
+	for _, key := range keys {
+		s, _ := findSetting(key)
+		colours.Success.Printf("✅ %s reset to %s\n", key, s.current())
+	}
+}
+
+// saveSettings writes several settings to the config file
+func saveSettings(values map[string]interface{}) error {
+	for key, value := range values {
+		if err := config.Save(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func unknownSetting(key string) {
+	var keys []string
+	for _, s := range settings {
+		keys = append(keys, s.key)
+	}
+	sort.Strings(keys)
+	colours.Error.Printf("❌ Unknown setting '%s'. Try one of: %s\n", key, strings.Join(keys, ", "))
+}
+
+// AddSettingsCommands adds the settings command group
+func (sn *StoryNest) AddSettingsCommands(rootCmd *cobra.Command) {
+	settingsCmd := &cobra.Command{
+		Use:   "settings",
+		Short: "⚙️ Configure TTS settings",
+		Long:  "Show and change voice, speed, volume and playback settings. Changes are saved to ~/.storynest/storynest.yaml",
+		Run:   sn.ListSettings,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "📋 Show every setting",
+		Run:   sn.ListSettings,
+	}
+
+	getCmd := &cobra.Command{
+		Use:   "get <key>",
+		Short: "🔍 Show one setting",
+		Args:  cobra.ExactArgs(1),
+		Run:   sn.GetSetting,
+	}
+
+	setCmd := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "✏️ Change a setting",
+		Args:  cobra.MinimumNArgs(2),
+		Run:   sn.SetSetting,
+	}
+
+	resetCmd := &cobra.Command{
+		Use:   "reset [key...]",
+		Short: "↩️ Put settings back to their defaults",
+		Run:   sn.ResetSettings,
+	}
+
+	settingsCmd.AddCommand(listCmd, getCmd, setCmd, resetCmd)
+	rootCmd.AddCommand(settingsCmd)
+}
@@ -0,0 +1,160 @@
+package nest
+
+import (
+	"storynest/internal/cli/scheme/colours"
+	"storynest/internal/story/tts"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// sleepFadeDuration is how long playback fades out before the sleep timer ends
+const sleepFadeDuration = time.Minute
+
+// sleepTimer ends playback gently after a set time
+type sleepTimer struct {
+	deadline time.Time
+	fade     *time.Timer
+	expire   *time.Timer
+	expired  chan struct{}
+}
+
+func (t *sleepTimer) stop() {
+	t.fade.Stop()
+	t.expire.Stop()
+}
+
+func (t *sleepTimer) remaining() time.Duration {
+	if left := time.Until(t.deadline); left > 0 {
+		return left
+	}
+	return 0
+}
+
+// startSleepTimer (re)starts the sleep timer. Volume fades out over the last minute,
+// then playback finishes at the next chunk boundary.
+func (sn *StoryNest) startSleepTimer(d time.Duration) {
+	sn.setSleepTimer(d)
+	colours.Info.Printf("😴 Sleep timer set for %s\n", d)
+}
+
+// setSleepTimer is startSleepTimer without the message
+func (sn *StoryNest) setSleepTimer(d time.Duration) {
+	sn.cancelSleepTimer()
+
+	fadeAfter, fadeOver := d-sleepFadeDuration, sleepFadeDuration
+	if fadeAfter < 0 {
+		fadeAfter, fadeOver = 0, d
+	}
+
+	t := &sleepTimer{
+		deadline: time.Now().Add(d),
+		expired:  make(chan struct{}),
+	}
+	t.fade = time.AfterFunc(fadeAfter, func() {
+		if fading, ok := sn.engine().(tts.FadingEngine); ok {
+			fading.FadeVolume(0, fadeOver)
+		}
+	})
+	t.expire = time.AfterFunc(d, func() {
+		close(t.expired)
+	})
+
+	sn.sleep = t
+}
+
+// cancelSleepTimer turns the sleep timer off and brings the volume back up if it had started fading
+func (sn *StoryNest) cancelSleepTimer() {
+	if sn.sleep == nil {
+		return
+	}
+
+	sn.sleep.stop()
+	sn.sleep = nil
+
+	if fading, ok := sn.engine().(tts.FadingEngine); ok {
+		fading.FadeVolume(1, time.Second)
+	}
+}
+
+// sleepExpired returns a channel that is closed when the sleep timer runs out, or nil if no timer is set
+func (sn *StoryNest) sleepExpired() <-chan struct{} {
+	if sn.sleep == nil {
+		return nil
+	}
+	return sn.sleep.expired
+}
+
+// sleepNearlyUp reports whether the sleep timer has started fading out (or already finished)
+func (sn *StoryNest) sleepNearlyUp() bool {
+	return sn.sleep != nil && sn.sleep.remaining() <= sleepFadeDuration
+}
+
+// toggleSleepTimer handles the 't' control: 't' switches the timer on or off, 't 30m' (or 't 30') sets it
+func (sn *StoryNest) toggleSleepTimer(arg string) {
+	if arg == "" {
+		if sn.sleep != nil {
+			sn.cancelSleepTimer()
+			colours.Warning.Println("⏰ Sleep timer off")
+			return
+		}
+		sn.startSleepTimer(viper.GetDuration("playback.sleep_timer"))
+		return
+	}
+
+	d, err := time.ParseDuration(arg)
+	if err != nil {
+		// A bare number means minutes
+		minutes, convErr := strconv.Atoi(arg)
+		if convErr != nil || minutes <= 0 {
+			colours.Error.Printf("❌ Invalid sleep time '%s' (try 20m or 20)\n", arg)
+			return
+		}
+		d = time.Duration(minutes) * time.Minute
+	}
+
+	if d <= 0 {
+		colours.Error.Println("❌ Sleep time must be positive")
+		return
+	}
+
+	sn.startSleepTimer(d)
+}
+
+// stopForSleep ends playback once the sleep timer runs out, letting the current sentence finish
+func (sn *StoryNest) stopForSleep() {
+	// The background stays down rather than coming back up as the narration ends
+	if sn.ambient != nil {
+		sn.ambient.Hush()
+	}
+
+	fading, ok := sn.engine().(tts.FadingEngine)
+	if !ok {
+		sn.engine().Stop()
+		return
+	}
+	if sn.isTTSPaused() {
+		// Nothing is left to fade, so the next story starts at full volume
+		sn.engine().Stop()
+		fading.FadeVolume(1, 0)
+		return
+	}
+	fading.StopAtBoundary()
+}
+
+// applySleepFlag remembers --sleep so the timer starts when playback does
+func (sn *StoryNest) applySleepFlag(cmd *cobra.Command) {
+	if d, err := cmd.Flags().GetDuration("sleep"); err == nil && d > 0 {
+		sn.sleepAfter = d
+	}
+}
+
+// armSleepTimer starts the timer requested with --sleep, if any
+func (sn *StoryNest) armSleepTimer() {
+	if sn.sleepAfter > 0 && sn.sleep == nil {
+		sn.startSleepTimer(sn.sleepAfter)
+		sn.sleepAfter = 0
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !windows
+
+package nest
+
+// keyMode isn't supported here, so controls are typed a line at a time
+func keyMode(fd int) (func(), bool) {
+	return nil, false
+}
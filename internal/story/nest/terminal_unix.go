@@ -0,0 +1,26 @@
+//go:build linux || darwin
+
+package nest
+
+import "golang.org/x/sys/unix"
+
+// keyMode turns off line buffering and echo on the terminal so single key presses
+// can be read straight away. Output and Ctrl+C carry on working as normal.
+func keyMode(fd int) (func(), bool) {
+	saved, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return nil, false
+	}
+
+	keys := *saved
+	keys.Lflag &^= unix.ICANON | unix.ECHO
+	keys.Cc[unix.VMIN] = 1
+	keys.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &keys); err != nil {
+		return nil, false
+	}
+
+	return func() {
+		_ = unix.IoctlSetTermios(fd, ioctlSetTermios, saved)
+	}, true
+}
@@ -0,0 +1,25 @@
+//go:build windows
+
+package nest
+
+import "golang.org/x/sys/windows"
+
+// keyMode turns off line input and echo on the console so single key presses can be
+// read straight away. Arrow keys arrive as escape sequences and Ctrl+C still works.
+func keyMode(fd int) (func(), bool) {
+	handle := windows.Handle(fd)
+
+	var saved uint32
+	if err := windows.GetConsoleMode(handle, &saved); err != nil {
+		return nil, false
+	}
+
+	keys := saved&^(windows.ENABLE_LINE_INPUT|windows.ENABLE_ECHO_INPUT) | windows.ENABLE_VIRTUAL_TERMINAL_INPUT
+	if err := windows.SetConsoleMode(handle, keys); err != nil {
+		return nil, false
+	}
+
+	return func() {
+		_ = windows.SetConsoleMode(handle, saved)
+	}, true
+}
@@ -0,0 +1,693 @@
+package nest
+
+import (
+	"fmt"
+	"os"
+	"storynest/internal/cli/scheme/colours"
+	"storynest/internal/domain/story"
+	"storynest/internal/story/tts"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-runewidth"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+// uiRefresh is how often the story browser redraws while nothing else is happening
+const uiRefresh = 500 * time.Millisecond
+
+// uiPreviewBytes is how much of a story is wrapped for the preview pane
+const uiPreviewBytes = 4000
+
+const (
+	enterFullScreen = "\033[?1049h\033[?25l"
+	leaveFullScreen = "\033[?25h\033[?1049l"
+)
+
+// uiPane is the part of the story browser the arrow keys move around in
+type uiPane int
+
+const (
+	paneLibraries uiPane = iota
+	paneStories
+	panePreview
+	paneCount
+)
+
+// uiLine is one line of a pane, drawn in style (or plain if style is nil)
+type uiLine struct {
+	text  string
+	style *color.Color
+}
+
+// storyBrowser is the full-screen story browser and player
+type storyBrowser struct {
+	sn *StoryNest
+
+	focus     uiPane
+	library   int // 0 for every library, otherwise the index into sn.libraries plus one
+	filter    string
+	filtering bool
+
+	stories []story.Item // stories in the chosen library that match the filter
+	cursor  int
+	top     int // first story shown in the list
+	preview int // lines the preview has been scrolled down
+
+	now         *playback
+	events      <-chan tts.Event
+	unsubscribe func()
+	sleep       <-chan struct{}
+
+	message string
+}
+
+// RunUI opens the full-screen story browser
+func (sn *StoryNest) RunUI(cmd *cobra.Command, args []string) {
+	// Start the engine first, so any warnings about it aren't drawn over
+	sn.engine()
+
+	restore, ok := sn.enterKeyMode()
+	if !ok {
+		colours.Error.Println("❌ The story browser needs a terminal. Try 'storynest read -i' instead.")
+		return
+	}
+	defer restore()
+
+	fmt.Print(enterFullScreen)
+	defer fmt.Print(leaveFullScreen)
+
+	b := &storyBrowser{sn: sn, focus: paneStories}
+	b.refilter()
+
+	keys := sn.inputKeys()
+	ticker := time.NewTicker(uiRefresh)
+	defer ticker.Stop()
+
+	for {
+		b.draw()
+
+		select {
+		case <-sn.ctx.Done():
+			b.finishPlayback(actionStop)
+			return
+		case <-ticker.C:
+		case ev := <-b.events:
+			b.handleEvent(ev)
+		case <-b.sleep:
+			b.sleep = nil
+			if b.now != nil {
+				b.now.sleeping = true
+				b.message = "😴 Sleep timer finished, stopping at the end of this part..."
+				sn.stopForSleep()
+			}
+		case key, ok := <-keys:
+			if !ok {
+				return
+			}
+			if quit := b.handleKey(key); quit {
+				b.stopPlayback()
+				return
+			}
+		}
+	}
+}
+
+// refilter rebuilds the story list from the chosen library and the filter
+func (b *storyBrowser) refilter() {
+	var source []story.Item
+	if b.library == 0 {
+		source = b.sn.getAllStories()
+	} else {
+		source = b.sn.libraries[b.library-1].Stories
+	}
+
+	words := strings.Fields(strings.ToLower(b.filter))
+	b.stories = b.stories[:0]
+	for _, item := range source {
+		if matchesFilter(item, words) {
+			b.stories = append(b.stories, item)
+		}
+	}
+
+	b.cursor, b.top, b.preview = 0, 0, 0
+}
+
+// matchesFilter reports whether every word appears in the story's title, author, genre or ID
+func matchesFilter(item story.Item, words []string) bool {
+	haystack := strings.ToLower(strings.Join([]string{item.Title, item.Author, item.Genre, item.ID}, " "))
+	for _, word := range words {
+		if !strings.Contains(haystack, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// selected returns the story under the cursor
+func (b *storyBrowser) selected() (story.Item, bool) {
+	if b.cursor < 0 || b.cursor >= len(b.stories) {
+		return story.Item{}, false
+	}
+	return b.stories[b.cursor], true
+}
+
+// handleKey acts on a key press, returning true when the browser should close
+func (b *storyBrowser) handleKey(key string) bool {
+	if b.filtering {
+		switch key {
+		case "enter":
+			b.filtering = false
+		case "esc":
+			b.filtering = false
+			b.filter = ""
+			b.refilter()
+		case "backspace":
+			if b.filter != "" {
+				_, size := utf8.DecodeLastRuneInString(b.filter)
+				b.filter = b.filter[:len(b.filter)-size]
+				b.refilter()
+			}
+		case "space":
+			b.filter += " "
+			b.refilter()
+		case "up", "down", "pgup", "pgdn":
+			b.moveCursor(key)
+		default:
+			if utf8.RuneCountInString(key) == 1 {
+				b.filter += key
+				b.refilter()
+			}
+		}
+		return false
+	}
+
+	switch key {
+	case "q":
+		return true
+	case "tab":
+		b.focus = (b.focus + 1) % paneCount
+	case "backtab":
+		b.focus = (b.focus + paneCount - 1) % paneCount
+	case "/":
+		b.filtering = true
+		b.focus = paneStories
+	case "esc":
+		if b.filter != "" {
+			b.filter = ""
+			b.refilter()
+		}
+	case "up", "down", "pgup", "pgdn":
+		b.moveCursor(key)
+	case "enter":
+		if b.focus == paneLibraries {
+			b.focus = paneStories
+		} else if item, ok := b.selected(); ok {
+			b.play(item, b.sn.bookmarkOffset(item))
+		}
+	default:
+		b.control(key)
+	}
+	return false
+}
+
+// moveCursor handles the arrow keys in whichever pane has focus
+func (b *storyBrowser) moveCursor(key string) {
+	step := map[string]int{"up": -1, "down": 1, "pgup": -10, "pgdn": 10}[key]
+
+	switch b.focus {
+	case paneLibraries:
+		library := max(0, min(len(b.sn.libraries), b.library+step))
+		if library != b.library {
+			b.library = library
+			b.refilter()
+		}
+	case paneStories:
+		cursor := max(0, min(len(b.stories)-1, b.cursor+step))
+		if cursor != b.cursor {
+			b.cursor = cursor
+			b.preview = 0
+		}
+	case panePreview:
+		b.preview = max(0, b.preview+step)
+	}
+}
+
+// control handles the playback keys
+func (b *storyBrowser) control(key string) {
+	sn := b.sn
+	if b.now == nil {
+		if key == "space" {
+			if item, ok := b.selected(); ok {
+				b.play(item, sn.bookmarkOffset(item))
+			}
+		}
+		return
+	}
+
+	switch key {
+	case "space", "p":
+		if !sn.engine().Capabilities().Pause {
+			b.message = fmt.Sprintf("⚠️ The %s engine can't pause", sn.getCurrentEngineName())
+		} else if b.now.paused {
+			sn.engine().Resume()
+		} else {
+			sn.engine().Pause()
+		}
+	case "left", "right", ",", ".":
+		if !sn.engine().Capabilities().Seek {
+			b.message = fmt.Sprintf("⚠️ The %s engine can't seek", sn.getCurrentEngineName())
+		} else if key == "left" || key == "right" {
+			b.seek(seekSentence, map[string]int{"left": -1, "right": 1}[key])
+		} else {
+			b.seek(seekParagraph, map[string]int{",": -1, ".": 1}[key])
+		}
+	case "+", "=":
+		if volume, err := sn.stepVolume(volumeStep); err == nil {
+			b.message = fmt.Sprintf("🔊 Volume %d%%", int(volume*100+0.5))
+		} else {
+			b.message = fmt.Sprintf("❌ %v", err)
+		}
+	case "-":
+		if volume, err := sn.stepVolume(-volumeStep); err == nil {
+			b.message = fmt.Sprintf("🔉 Volume %d%%", int(volume*100+0.5))
+		} else {
+			b.message = fmt.Sprintf("❌ %v", err)
+		}
+	case "[", "]":
+		delta := speedStep
+		if key == "[" {
+			delta = -speedStep
+		}
+		if speed, err := sn.stepSpeed(delta); err == nil {
+			b.message = speedNotice(speed)
+		} else {
+			b.message = fmt.Sprintf("❌ %v", err)
+		}
+	case "r":
+		b.restart(0)
+		b.message = "⏮️  Starting again from the beginning"
+	case "t":
+		if sn.sleep != nil {
+			sn.cancelSleepTimer()
+			b.sleep = nil
+			b.message = "⏰ Sleep timer off"
+		} else {
+			sn.setSleepTimer(viper.GetDuration("playback.sleep_timer"))
+			b.sleep = sn.sleepExpired()
+			b.message = "😴 Sleep timer on"
+		}
+	case "s":
+		b.stopPlayback()
+		b.message = "⏹️  Stopped"
+	}
+}
+
+// seek moves playback by a sentence or paragraph, starting again if the engine can't seek there itself
+func (b *storyBrowser) seek(unit seekUnit, dir int) {
+	pb := b.now
+	target, ok := seekTarget(storyBoundaries(pb.item, unit), b.sn.currentStoryOffset(pb.base), dir)
+	if !ok {
+		if dir < 0 {
+			b.message = "⏮️  Already at the beginning"
+		} else {
+			b.message = fmt.Sprintf("⏭️  No next %s", unit)
+		}
+		return
+	}
+
+	arrow := "⏩"
+	if dir < 0 {
+		arrow = "⏪"
+	}
+	b.message = arrow + "  " + seekMessage(unit, dir)
+	if !b.sn.seekEngine(pb.base, target) {
+		b.restart(target)
+	}
+}
+
+// play starts reading a story, stopping anything already playing
+func (b *storyBrowser) play(item story.Item, offset int) {
+	b.stopPlayback()
+
+	b.sn.engine().SetBookContext(extractProviderFromStoryID(item.ID), extractBookIDFromStoryID(item.ID))
+	b.now = newPlayback(item, false)
+	if offset > 0 {
+		b.message = fmt.Sprintf("📑 Carrying on from %d%%", offset*100/len(item.Content))
+	} else {
+		b.message = ""
+	}
+	b.speak(offset)
+}
+
+// restart reads the playing story again from offset, keeping its clock running
+func (b *storyBrowser) restart(offset int) {
+	if b.now == nil {
+		return
+	}
+	b.unsubscribe()
+	b.events = nil
+	b.sn.engine().Stop()
+	b.speak(offset)
+}
+
+// speak hands the playing story to the engine from offset
+func (b *storyBrowser) speak(offset int) {
+	pb := b.now
+	pb.base = offset
+	pb.chunk, pb.chunks = 0, 0
+	pb.setPaused(false)
+
+	// Synthesis can take a while, so say what's happening first
+	preparing := b.message
+	b.message = "⏳ Preparing audio..."
+	b.draw()
+	b.message = preparing
+
+	events, unsubscribe := b.sn.engine().Subscribe()
+	if err := b.sn.engine().SpeakContext(b.sn.ctx, pb.item.Content[offset:]); err != nil {
+		unsubscribe()
+		b.now = nil
+		b.message = fmt.Sprintf("❌ TTS Error: %v", err)
+		return
+	}
+	b.events, b.unsubscribe = events, unsubscribe
+	b.sleep = b.sn.sleepExpired()
+}
+
+// stopPlayback stops the playing story and bookmarks where it got to
+func (b *storyBrowser) stopPlayback() {
+	if b.now == nil {
+		return
+	}
+	b.sn.engine().Stop()
+	b.finishPlayback(actionStop)
+}
+
+// finishPlayback forgets the playing story, updating its bookmark
+func (b *storyBrowser) finishPlayback(action playbackAction) {
+	if b.now == nil {
+		return
+	}
+	b.unsubscribe()
+	b.sn.updateBookmark(b.now.item, b.now.base, action)
+	b.now, b.events, b.unsubscribe = nil, nil, nil
+}
+
+// handleEvent follows the engine's playback events
+func (b *storyBrowser) handleEvent(ev tts.Event) {
+	if b.now == nil {
+		return
+	}
+
+	switch ev.Type {
+	case tts.EventChunkStarted:
+		b.now.chunk, b.now.chunks = ev.Chunk, ev.Chunks
+	case tts.EventPaused:
+		b.now.setPaused(true)
+	case tts.EventResumed:
+		b.now.setPaused(false)
+	case tts.EventError:
+		b.message = fmt.Sprintf("❌ TTS Error: %v", ev.Err)
+	case tts.EventEngineSwitched:
+		b.message = engineSwitchNotice(ev)
+	case tts.EventFinished:
+		title, action := b.now.item.Title, b.now.finished()
+		b.finishPlayback(action)
+		if action == actionSleep {
+			b.message = "😴 Sleep tight! 🌙"
+		} else {
+			b.message = fmt.Sprintf("✅ Finished %s", title)
+		}
+	}
+}
+
+// draw redraws the whole screen: the panes, the now-playing panel and the key help
+func (b *storyBrowser) draw() {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width < 40 || height < 12 {
+		width, height = max(width, 80), max(height, 24)
+	}
+
+	// Header and rule above the panes; rule, two now-playing lines, message and help below
+	bodyHeight := height - 7
+	libWidth := min(24, width/5)
+	listWidth := (width - libWidth - 6) / 2
+	previewWidth := width - libWidth - listWidth - 6
+
+	libraries := b.libraryLines(bodyHeight)
+	stories := b.storyLines(bodyHeight)
+	preview := b.previewLines(previewWidth, bodyHeight)
+
+	var s strings.Builder
+	s.WriteString("\033[H")
+
+	header := "🏠 StoryNest"
+	if b.filtering || b.filter != "" {
+		cursor := ""
+		if b.filtering {
+			cursor = "▏"
+		}
+		header += "   🔎 " + b.filter + cursor
+	}
+	writeRow(&s, colours.Title.Sprint(fitWidth(header, width)))
+	writeRow(&s, strings.Repeat("─", width))
+
+	for row := 0; row < bodyHeight; row++ {
+		s.WriteString(" ")
+		s.WriteString(paneCell(libraries, row, libWidth))
+		s.WriteString(" │ ")
+		s.WriteString(paneCell(stories, row, listWidth))
+		s.WriteString(" │ ")
+		s.WriteString(paneCell(preview, row, previewWidth))
+		writeRow(&s, "")
+	}
+
+	writeRow(&s, strings.Repeat("─", width))
+	for _, line := range b.nowPlayingLines(width - 2) {
+		writeRow(&s, " "+paneCell([]uiLine{line}, 0, width-2))
+	}
+	writeRow(&s, " "+fitWidth(b.message, width-2))
+	s.WriteString(" " + colours.Info.Sprint(fitWidth(b.helpLine(), width-2)))
+	s.WriteString("\033[K\033[J")
+
+	fmt.Print(s.String())
+}
+
+// writeRow finishes a line of the screen, clearing anything left over from the last draw
+func writeRow(s *strings.Builder, text string) {
+	s.WriteString(text)
+	s.WriteString("\033[K\n")
+}
+
+// paneCell renders row of a pane padded to width, or blank if the pane is shorter
+func paneCell(lines []uiLine, row, width int) string {
+	if row >= len(lines) {
+		return strings.Repeat(" ", width)
+	}
+	text := fitWidth(lines[row].text, width)
+	if lines[row].style == nil {
+		return text
+	}
+	return lines[row].style.Sprint(text)
+}
+
+// fitWidth cuts or pads s to exactly width terminal columns
+func fitWidth(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	s = truncateWidth(s, width)
+	return runewidth.FillRight(s, width)
+}
+
+// paneTitle styles a pane's heading, brighter when the pane has focus
+func (b *storyBrowser) paneTitle(pane uiPane, title string) uiLine {
+	if b.focus == pane {
+		return uiLine{text: "▸ " + title, style: colours.Title}
+	}
+	return uiLine{text: "  " + title, style: colours.Info}
+}
+
+// cursorStyle is how the selected row of a pane is shown
+func (b *storyBrowser) cursorStyle(pane uiPane) *color.Color {
+	if b.focus == pane {
+		return colours.Prompt
+	}
+	return colours.Author
+}
+
+func (b *storyBrowser) libraryLines(height int) []uiLine {
+	lines := []uiLine{b.paneTitle(paneLibraries, "Libraries")}
+
+	names := []string{fmt.Sprintf("All stories (%d)", len(b.sn.getAllStories()))}
+	for _, lib := range b.sn.libraries {
+		names = append(names, fmt.Sprintf("%s (%d)", lib.Name, len(lib.Stories)))
+	}
+
+	for i, name := range names {
+		if len(lines) >= height {
+			break
+		}
+		if i == b.library {
+			lines = append(lines, uiLine{text: "> " + name, style: b.cursorStyle(paneLibraries)})
+		} else {
+			lines = append(lines, uiLine{text: "  " + name})
+		}
+	}
+	return lines
+}
+
+func (b *storyBrowser) storyLines(height int) []uiLine {
+	lines := []uiLine{b.paneTitle(paneStories, fmt.Sprintf("Stories (%d)", len(b.stories)))}
+	if len(b.stories) == 0 {
+		return append(lines, uiLine{text: "  No stories match", style: colours.Warning})
+	}
+
+	// Keep the cursor on screen
+	visible := max(1, height-1)
+	if b.cursor < b.top {
+		b.top = b.cursor
+	}
+	if b.cursor >= b.top+visible {
+		b.top = b.cursor - visible + 1
+	}
+
+	for i := b.top; i < len(b.stories) && i < b.top+visible; i++ {
+		item := b.stories[i]
+		marker := "  "
+		if b.now != nil && b.now.item.ID == item.ID {
+			marker = "♪ "
+		}
+		line := uiLine{text: marker + item.Title}
+		if i == b.cursor {
+			line = uiLine{text: "> " + item.Title, style: b.cursorStyle(paneStories)}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// previewLines shows the selected story's details and the start of its text, or the
+// text being read if it is the story that's playing
+func (b *storyBrowser) previewLines(width, height int) []uiLine {
+	lines := []uiLine{b.paneTitle(panePreview, "Details")}
+	item, ok := b.selected()
+	if !ok {
+		return lines
+	}
+
+	lines = append(lines,
+		uiLine{text: item.Title, style: colours.Title},
+		uiLine{text: "by " + item.Author, style: colours.Author},
+		uiLine{text: fmt.Sprintf("%s · %s · %s", item.AgeGroup, item.Genre, item.Duration)},
+	)
+	if offset := b.sn.bookmarkOffset(item); offset > 0 && (b.now == nil || b.now.item.ID != item.ID) {
+		lines = append(lines, uiLine{text: fmt.Sprintf("📑 Bookmarked at %d%%", offset*100/len(item.Content)), style: colours.Info})
+	}
+	lines = append(lines, uiLine{})
+
+	var body []uiLine
+	for _, l := range wrapText(item.Description, width) {
+		body = append(body, uiLine{text: item.Description[l.start:l.end]})
+	}
+	body = append(body, uiLine{})
+
+	// Follow along with the story that's playing
+	start := 0
+	if b.now != nil && b.now.item.ID == item.ID {
+		start = b.sn.currentStoryOffset(b.now.base)
+		body = append(body, uiLine{text: "Now reading:", style: colours.Info})
+	}
+	end := min(len(item.Content), start+uiPreviewBytes)
+	for end < len(item.Content) && !utf8.RuneStart(item.Content[end]) {
+		end++
+	}
+	text := item.Content[start:end]
+	for _, l := range wrapText(text, width) {
+		body = append(body, uiLine{text: text[l.start:l.end]})
+	}
+
+	b.preview = min(b.preview, max(0, len(body)-1))
+	return append(lines, body[b.preview:]...)
+}
+
+// nowPlayingLines shows what's playing and how far through it is
+func (b *storyBrowser) nowPlayingLines(width int) []uiLine {
+	pb := b.now
+	if pb == nil {
+		return []uiLine{
+			{text: "Nothing playing. Pick a story and press Enter to listen.", style: colours.Info},
+			{},
+		}
+	}
+
+	icon := "▶️"
+	if pb.paused {
+		icon = "⏸️"
+	}
+	parts := []string{icon + " " + pb.item.Title, formatClock(pb.elapsed())}
+	if pb.chunks > 1 {
+		parts = append(parts, fmt.Sprintf("part %d/%d", pb.chunk+1, pb.chunks))
+	}
+	if b.sn.sleep != nil {
+		parts = append(parts, "😴 "+formatClock(b.sn.sleep.remaining()))
+	}
+	parts = append(parts, fmt.Sprintf("🔊 %d%%", int(b.sn.volume*100+0.5)), fmt.Sprintf("%.1fx", b.sn.speed))
+
+	progress := 0.0
+	if len(pb.item.Content) > 0 {
+		progress = float64(b.sn.currentStoryOffset(pb.base)) / float64(len(pb.item.Content))
+	}
+	barWidth := max(10, width-8)
+	filled := min(barWidth, int(progress*float64(barWidth)))
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+
+	return []uiLine{
+		{text: strings.Join(parts, " · "), style: colours.Success},
+		{text: fmt.Sprintf("%s %3d%%", bar, int(progress*100)), style: colours.Info},
+	}
+}
+
+// helpLine lists the keys that do something right now
+func (b *storyBrowser) helpLine() string {
+	if b.filtering {
+		return "type to filter · ↑/↓ move · enter done · esc clear"
+	}
+	help := "tab pane · ↑/↓ move · enter play · / filter · q quit"
+	if b.now != nil {
+		caps := b.sn.engine().Capabilities()
+		var keys []string
+		if caps.Seek {
+			keys = append(keys, "←/→ sentence", ",/. paragraph")
+		}
+		if caps.Pause {
+			keys = append([]string{"space pause"}, keys...)
+		}
+		if caps.CanSetVolume() {
+			keys = append(keys, "+/- volume")
+		}
+		if caps.CanSetSpeed() {
+			keys = append(keys, "[/] speed")
+		}
+		keys = append(keys, "r restart", "t sleep timer", "s stop", help)
+		help = strings.Join(keys, " · ")
+	}
+	return help
+}
+
+// AddUICommands adds the full-screen story browser
+func (sn *StoryNest) AddUICommands(rootCmd *cobra.Command) {
+	uiCmd := &cobra.Command{
+		Use:   "ui",
+		Short: "🖥️ Browse and play stories full screen",
+		Long:  "Browse libraries, filter stories as you type, preview them and control playback in a full-screen view",
+		Run:   sn.RunUI,
+	}
+
+	rootCmd.AddCommand(uiCmd)
+}
@@ -0,0 +1,247 @@
+package tts
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+	"github.com/faiface/beep/mp3"
+	"github.com/faiface/beep/speaker"
+	"github.com/faiface/beep/wav"
+)
+
+// Generated noise sources that can be used instead of an audio file
+const (
+	NoiseWhite = "white"
+	NoisePink  = "pink"
+	NoiseBrown = "brown"
+)
+
+// duckFade is how quickly the ambient layer dips and recovers around speech
+const duckFade = 500 * time.Millisecond
+
+// Ambient is a looping background sound (rain, white noise...) mixed underneath the narration
+type Ambient struct {
+	mu       sync.Mutex
+	source   string
+	volume   float64
+	duck     float64
+	level    float64 // current ducking/fading multiplier
+	ducked   bool
+	hushed   bool // no longer comes back up when speech stops
+	closer   func() error
+	ctrl     *beep.Ctrl
+	effect   *effects.Volume
+	fader    volumeFader
+	finished chan struct{}
+}
+
+// NewAmbient prepares a background sound. source is one of the noise names or the path
+// to an MP3/WAV file. volume is the level relative to the narration and duck is the
+// fraction of that volume kept while speech is playing.
+func NewAmbient(source string, volume, duck float64) (*Ambient, error) {
+	a := &Ambient{
+		source: source,
+		volume: volume,
+		duck:   duck,
+		level:  1.0,
+	}
+
+	streamer, err := a.open()
+	if err != nil {
+		return nil, err
+	}
+
+	a.ctrl = &beep.Ctrl{Streamer: streamer}
+	a.effect = &effects.Volume{Streamer: a.ctrl}
+	applyVolume(a.effect, a.volume*a.level)
+
+	return a, nil
+}
+
+// open builds the looping stream for the configured source
+func (a *Ambient) open() (beep.Streamer, error) {
+	switch strings.ToLower(a.source) {
+	case NoiseWhite, NoisePink, NoiseBrown:
+		return newNoise(strings.ToLower(a.source)), nil
+	}
+
+	f, err := os.Open(a.source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ambient sound %s: %w", a.source, err)
+	}
+
+	var streamer beep.StreamSeekCloser
+	var format beep.Format
+	switch strings.ToLower(filepath.Ext(a.source)) {
+	case ".mp3":
+		streamer, format, err = mp3.Decode(f)
+	case ".wav":
+		streamer, format, err = wav.Decode(f)
+	default:
+		f.Close()
+		return nil, fmt.Errorf("unsupported ambient sound %s (use an .mp3/.wav file or white, pink, brown)", a.source)
+	}
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to decode ambient sound %s: %w", a.source, err)
+	}
+
+	a.closer = streamer.Close
+	return toOutputRate(beep.Loop(-1, streamer), format.SampleRate), nil
+}
+
+// Source returns the file or noise name being played
+func (a *Ambient) Source() string {
+	return a.source
+}
+
+// Start begins playing the background sound on the shared speaker
+func (a *Ambient) Start() error {
+	if err := initSpeaker(); err != nil {
+		return fmt.Errorf("failed to open audio output: %w", err)
+	}
+
+	a.mu.Lock()
+	a.finished = make(chan struct{})
+	finished := a.finished
+	a.mu.Unlock()
+
+	output.Play(beep.Seq(a.effect, beep.Callback(func() {
+		close(finished)
+	})))
+	return nil
+}
+
+// SetVolume changes the background level relative to the narration
+func (a *Ambient) SetVolume(volume float64) {
+	speaker.Lock()
+	a.volume = volume
+	applyVolume(a.effect, a.volume*a.level)
+	speaker.Unlock()
+}
+
+// Duck lowers the background while speech is playing and brings it back up afterwards
+func (a *Ambient) Duck(speaking bool) {
+	a.mu.Lock()
+	if a.ducked == speaking || a.hushed {
+		a.mu.Unlock()
+		return
+	}
+	a.ducked = speaking
+	a.mu.Unlock()
+
+	target := 1.0
+	if speaking {
+		target = a.duck
+	}
+	a.fadeTo(target, duckFade)
+}
+
+// Hush keeps the background no louder than it is while speech plays, even once the
+// speech stops, e.g. because the sleep timer has faded the narration away
+func (a *Ambient) Hush() {
+	a.mu.Lock()
+	a.hushed = true
+	a.mu.Unlock()
+
+	speaker.Lock()
+	level := min(a.level, a.duck)
+	speaker.Unlock()
+	a.fadeTo(level, 0)
+}
+
+// FadeOut ramps the background down to silence and then stops it
+func (a *Ambient) FadeOut(over time.Duration) {
+	a.fadeTo(0, over)
+	time.AfterFunc(over+fadeStep, func() {
+		a.Stop()
+	})
+}
+
+func (a *Ambient) fadeTo(target float64, over time.Duration) {
+	speaker.Lock()
+	from := a.level
+	speaker.Unlock()
+
+	a.fader.start(from, target, over, func(l float64, current func() bool) {
+		speaker.Lock()
+		defer speaker.Unlock()
+		if !current() {
+			return
+		}
+		a.level = l
+		applyVolume(a.effect, a.volume*a.level)
+	})
+}
+
+// Stop ends the background sound straight away
+func (a *Ambient) Stop() {
+	a.fader.cancel()
+
+	speaker.Lock()
+	a.ctrl.Streamer = nil
+	speaker.Unlock()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closer != nil {
+		a.closer()
+		a.closer = nil
+	}
+}
+
+// Done is closed once the background sound has stopped playing
+func (a *Ambient) Done() <-chan struct{} {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.finished
+}
+
+// noise generates white, pink or brown noise forever
+type noise struct {
+	kind string
+	rng  *rand.Rand
+	// pink noise filter state (Paul Kellet's economy method)
+	b0, b1, b2 float64
+	// brown noise integrator state
+	last float64
+}
+
+func newNoise(kind string) *noise {
+	return &noise{kind: kind, rng: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+func (n *noise) Stream(samples [][2]float64) (int, bool) {
+	for i := range samples {
+		white := n.rng.Float64()*2 - 1
+
+		var v float64
+		switch n.kind {
+		case NoisePink:
+			n.b0 = 0.99765*n.b0 + white*0.0990460
+			n.b1 = 0.96300*n.b1 + white*0.2965164
+			n.b2 = 0.57000*n.b2 + white*1.0526913
+			v = (n.b0 + n.b1 + n.b2 + white*0.1848) * 0.2
+		case NoiseBrown:
+			n.last = (n.last + 0.02*white) / 1.02
+			v = n.last * 3.5
+		default:
+			v = white * 0.5
+		}
+
+		samples[i][0] = v
+		samples[i][1] = v
+	}
+	return len(samples), true
+}
+
+func (n *noise) Err() error {
+	return nil
+}
@@ -0,0 +1,33 @@
+package tts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/faiface/beep/speaker"
+)
+
+func TestHushedAmbientStaysDown(t *testing.T) {
+	useTestOutput(t)
+	amb, err := NewAmbient(NoisePink, 1, 0.3)
+	if err != nil {
+		t.Fatalf("NewAmbient: %v", err)
+	}
+	if err := amb.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer amb.Stop()
+
+	// The sleep timer has faded the narration away, so the speech ending mustn't bring the background back up
+	amb.Duck(true)
+	amb.Hush()
+	amb.Duck(false)
+	time.Sleep(3 * fadeStep)
+
+	speaker.Lock()
+	level := amb.level
+	speaker.Unlock()
+	if level != 0.3 {
+		t.Errorf("level = %v, want the ducked 0.3", level)
+	}
+}
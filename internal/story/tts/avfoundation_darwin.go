@@ -3,158 +3,153 @@
 package tts
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"regexp"
+	"strings"
 	"sync"
 )
 
-// AVFoundationEngine implements macOS AVFoundation TTS
+// avSpeedRange is relative to the default of 175 words per minute
+var avSpeedRange = Range{Min: 0.1, Max: 3.0}
+
+// avChunkLimit splits stories into a few sentences per 'say' run, so the first audio is ready quickly
+const avChunkLimit = 1000
+
+// AVFoundationEngine synthesizes WAV with macOS's 'say' and plays it through the shared player
 type AVFoundationEngine struct {
-	config  Config
-	playing bool
-	paused  bool
-	mutex   sync.RWMutex
+	*player
+	voice string
+	speed float64
+	mutex sync.RWMutex
 }
 
 // newAVFoundationEngine creates a new macOS AVFoundation TTS engine
 func newAVFoundationEngine(config Config) (*AVFoundationEngine, error) {
 	engine := &AVFoundationEngine{
-		config: config,
+		voice: config.Voice,
+		speed: config.Speed,
 	}
+	if engine.speed <= 0 {
+		engine.speed = 1.0
+	}
+	engine.player = newPlayer(engine, EngineTypeAVFoundation.String(), OpenAudioCache(), config.Volume)
 
 	return engine, nil
 }
 
-func (av *AVFoundationEngine) Speak(text string) error {
-	av.mutex.Lock()
-	defer av.mutex.Unlock()
-
-	if av.playing {
-		return fmt.Errorf("already playing")
-	}
-
-	av.playing = true
-
-	// Use macOS built-in 'say' command
-	go func() {
-		defer func() {
-			av.mutex.Lock()
-			av.playing = false
-			av.paused = false
-			av.mutex.Unlock()
-		}()
-
-		args := []string{}
-
-		// Set voice if specified
-		if av.config.Voice != "" && av.config.Voice != "default" {
-			args = append(args, "-v", av.config.Voice)
-		}
-
-		// Set rate (words per minute, default is ~175)
-		rate := fmt.Sprintf("%.0f", 175*av.config.Speed)
-		args = append(args, "-r", rate)
-
-		// Add text
-		args = append(args, text)
-
-		cmd := exec.Command("say", args...)
-		if err := cmd.Run(); err != nil {
-			fmt.Printf("AVFoundation error: %v\n", err)
-		}
-	}()
-
-	return nil
+// SynthesisParams are the voice and speed new audio is made with
+func (av *AVFoundationEngine) SynthesisParams() SynthesisParams {
+	av.mutex.RLock()
+	defer av.mutex.RUnlock()
+	return SynthesisParams{Engine: EngineTypeAVFoundation.String(), Voice: av.voice, Speed: av.speed, ChunkerVersion: chunkerVersion}
 }
 
-func (av *AVFoundationEngine) Stop() error {
-	av.mutex.Lock()
-	defer av.mutex.Unlock()
-
-	// Kill any running 'say' processes
-	exec.Command("killall", "say").Run()
-
-	av.playing = false
-	av.paused = false
-	return nil
+func (av *AVFoundationEngine) ChunkLimit() int {
+	return avChunkLimit
 }
 
-func (av *AVFoundationEngine) Pause() error {
-	av.mutex.Lock()
-	defer av.mutex.Unlock()
+func (av *AVFoundationEngine) AudioFormat() string {
+	return "wav"
+}
 
-	if !av.playing || av.paused {
-		return nil
+// Synthesize has 'say' write a chunk to a WAV file and returns its contents. The text
+// goes in on stdin so it is never taken for an option.
+func (av *AVFoundationEngine) Synthesize(ctx context.Context, text string, params SynthesisParams) ([]byte, []WordMark, error) {
+	out, err := os.CreateTemp("", "storynest-say-*.wav")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create temporary WAV file: %w", err)
 	}
+	out.Close()
+	defer os.Remove(out.Name())
 
-	av.paused = true
-	return nil
-}
+	args := []string{"-o", out.Name(), "--file-format=WAVE", "--data-format=LEI16@22050"}
 
-func (av *AVFoundationEngine) Resume() error {
-	av.mutex.Lock()
-	defer av.mutex.Unlock()
+	// Set voice if specified
+	if params.Voice != "" && params.Voice != "default" {
+		args = append(args, "-v", params.Voice)
+	}
+
+	// Set rate (words per minute, default is ~175)
+	args = append(args, "-r", fmt.Sprintf("%.0f", 175*params.Speed))
 
-	if !av.paused {
-		return nil
+	cmd := exec.CommandContext(ctx, "say", args...)
+	cmd.Stdin = strings.NewReader(text)
+	if err := cmd.Run(); err != nil {
+		return nil, nil, fmt.Errorf("say failed: %w", err)
 	}
 
-	av.paused = false
-	return nil
+	data, err := os.ReadFile(out.Name())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read audio from say: %w", err)
+	}
+	return data, nil, nil
 }
 
 func (av *AVFoundationEngine) SetVoice(voice string) error {
 	av.mutex.Lock()
-	defer av.mutex.Unlock()
-
-	av.config.Voice = voice
+	av.voice = voice
+	av.mutex.Unlock()
+	av.resynthesize()
 	return nil
 }
 
 func (av *AVFoundationEngine) SetSpeed(speed float64) error {
-	av.mutex.Lock()
-	defer av.mutex.Unlock()
-
-	if speed <= 0 || speed > 3.0 {
-		return fmt.Errorf("speed must be between 0.1 and 3.0")
+	if err := avSpeedRange.check("speed", speed); err != nil {
+		return err
 	}
 
-	av.config.Speed = speed
-	return nil
-}
-
-func (av *AVFoundationEngine) SetVolume(volume float64) error {
 	av.mutex.Lock()
-	defer av.mutex.Unlock()
-
-	if volume < 0 || volume > 1.0 {
-		return fmt.Errorf("volume must be between 0 and 1.0")
-	}
-
-	av.config.Volume = volume
+	av.speed = speed
+	av.mutex.Unlock()
+	av.resynthesize()
 	return nil
 }
 
-func (av *AVFoundationEngine) IsPlaying() bool {
-	av.mutex.RLock()
-	defer av.mutex.RUnlock()
-	return av.playing && !av.paused
+func (av *AVFoundationEngine) SpeedRange() Range {
+	return avSpeedRange
 }
 
-func (av *AVFoundationEngine) IsPaused() bool {
-	av.mutex.RLock()
-	defer av.mutex.RUnlock()
-	return av.paused
+func (av *AVFoundationEngine) GetAvailableVoices() ([]string, error) {
+	voices, err := av.GetVoiceInfo()
+	if err != nil {
+		return nil, err
+	}
+	return voiceNames(voices), nil
 }
 
-func (av *AVFoundationEngine) GetAvailableVoices() ([]string, error) {
+// GetVoiceInfo lists the voices installed for 'say'. macOS doesn't report their gender.
+func (av *AVFoundationEngine) GetVoiceInfo() ([]VoiceInfo, error) {
 	cmd := exec.Command("say", "-v", "?")
 	output, err := cmd.Output()
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to list voices: %w", err)
 	}
 
-	// Parse the output to extract voice names
-	// The output format is: "VoiceName    language    # description"
-	return []string{"Alex", "Samantha", "Victoria", "Daniel"}, nil
+	voices := parseSayVoices(string(output))
+	sortVoices(voices)
+	return voices, nil
+}
+
+// sayVoiceLine matches a line of 'say -v ?': "VoiceName    language    # sample sentence".
+// Names can contain spaces, e.g. "Eddy (English (UK))".
+var sayVoiceLine = regexp.MustCompile(`^(.+?)\s+([a-z]{2,3}_[A-Za-z0-9]+)\s+#\s*(.*)$`)
+
+func parseSayVoices(output string) []VoiceInfo {
+	var voices []VoiceInfo
+	for _, line := range strings.Split(output, "\n") {
+		m := sayVoiceLine.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		voices = append(voices, VoiceInfo{
+			Name:         m[1],
+			LanguageCode: languageTag(m[2]),
+			Natural:      strings.Contains(m[1], "Premium") || strings.Contains(m[1], "Enhanced"),
+			Description:  m[3],
+		})
+	}
+	return voices
 }
@@ -0,0 +1,10 @@
+//go:build !darwin
+
+package tts
+
+import "fmt"
+
+// newAVFoundationEngine explains that AVFoundation is only on macOS
+func newAVFoundationEngine(config Config) (Engine, error) {
+	return nil, fmt.Errorf("AVFoundation engine only supports macOS")
+}
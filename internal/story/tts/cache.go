@@ -0,0 +1,579 @@
+package tts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"storynest/internal/config"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// ErrCacheFull is returned by Prefetch when the cache has no room for more audio
+var ErrCacheFull = errors.New("the audio cache is full")
+
+// cacheIndexFile records when each cached book was last played and whether it is pinned
+const cacheIndexFile = "audio_cache.json"
+
+// AudioCache keeps synthesized audio on disk as root/<provider>/<engine>/<book>.
+// Once it grows past its size limit the least recently used books are evicted,
+// apart from pinned ones.
+type AudioCache struct {
+	root     string
+	maxBytes int64 // 0 means no limit
+	enabled  bool
+	mu       sync.Mutex
+	held     map[string]int // books being prefetched, which are never evicted
+}
+
+// CachedBook is the audio kept for one book, across every engine that has read it
+type CachedBook struct {
+	Provider   string
+	BookID     string
+	Engines    []string
+	Voices     []string // as "engine/voice", from the manifests
+	Files      int
+	Bytes      int64
+	Modified   time.Time // when audio was last added
+	LastPlayed time.Time // zero if it was never played, e.g. only exported
+	Pinned     bool
+}
+
+// LastUsed is when the book was last played or had audio added, whichever is later,
+// so audio prefetched for later isn't the first to be evicted
+func (b CachedBook) LastUsed() time.Time {
+	if b.Modified.After(b.LastPlayed) {
+		return b.Modified
+	}
+	return b.LastPlayed
+}
+
+// CacheProblem is a file in the cache that can't be trusted
+type CacheProblem struct {
+	Book    CachedBook
+	Engine  string
+	File    string
+	Problem string
+}
+
+// cacheRecord is what the index remembers about a book
+type cacheRecord struct {
+	LastPlayed time.Time `json:"last_played"`
+	Pinned     bool      `json:"pinned,omitempty"`
+}
+
+// NewAudioCache creates a cache in root holding at most maxMB megabytes (0 for no limit).
+// A disabled cache keeps only the audio of the book being played.
+func NewAudioCache(root string, maxMB int, enabled bool) *AudioCache {
+	return &AudioCache{root: root, maxBytes: int64(maxMB) * 1024 * 1024, enabled: enabled}
+}
+
+// OpenAudioCache creates the cache described by the tts.cache_* settings
+func OpenAudioCache() *AudioCache {
+	return NewAudioCache(config.CacheDir(), viper.GetInt("tts.cache_max_size_mb"), viper.GetBool("tts.cache_enabled"))
+}
+
+// Root is the directory the cache lives in
+func (c *AudioCache) Root() string {
+	return c.root
+}
+
+// Enabled reports whether audio is kept after it has been played
+func (c *AudioCache) Enabled() bool {
+	return c.enabled
+}
+
+// Limit is the most the cache may hold in bytes, or 0 if it may grow without limit
+func (c *AudioCache) Limit() int64 {
+	return c.maxBytes
+}
+
+// BookDir is where an engine keeps the audio for a book. With caching disabled
+// it is a scratch directory that only ever holds the book being played.
+func (c *AudioCache) BookDir(provider, engine, bookID string) string {
+	if !c.enabled {
+		return filepath.Join(scratchDir(), provider, engine, bookID)
+	}
+	return filepath.Join(c.root, provider, engine, bookID)
+}
+
+// scratchDir holds the audio being played while caching is disabled
+func scratchDir() string {
+	return filepath.Join(os.TempDir(), "storynest-audio")
+}
+
+// Played records that a book is being played now, so it is the last to be evicted.
+// With caching disabled it throws away the audio of every other book instead.
+func (c *AudioCache) Played(provider, bookID string) error {
+	if !c.enabled {
+		return clearScratch(provider, bookID)
+	}
+
+	return c.update(func(index map[string]cacheRecord) {
+		record := index[bookKey(provider, bookID)]
+		record.LastPlayed = time.Now()
+		index[bookKey(provider, bookID)] = record
+	})
+}
+
+// clearScratch removes everything from the scratch directory except the given book
+func clearScratch(provider, bookID string) error {
+	books, err := scanBooks(scratchDir())
+	if err != nil {
+		return err
+	}
+	for _, book := range books {
+		if book.Provider == provider && book.BookID == bookID {
+			continue
+		}
+		if err := removeBook(scratchDir(), book); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pin keeps a book's audio from ever being evicted, or unpins it again
+func (c *AudioCache) Pin(provider, bookID string, pinned bool) error {
+	return c.update(func(index map[string]cacheRecord) {
+		record := index[bookKey(provider, bookID)]
+		record.Pinned = pinned
+		index[bookKey(provider, bookID)] = record
+	})
+}
+
+// PinnedBooks lists the pinned books, whether or not any of their audio is cached yet
+func (c *AudioCache) PinnedBooks() ([]CachedBook, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	index, err := c.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	var pinned []CachedBook
+	for key, record := range index {
+		provider, bookID, ok := strings.Cut(key, "/")
+		if ok && record.Pinned {
+			pinned = append(pinned, CachedBook{Provider: provider, BookID: bookID, LastPlayed: record.LastPlayed, Pinned: true})
+		}
+	}
+	sort.Slice(pinned, func(i, j int) bool {
+		return bookKey(pinned[i].Provider, pinned[i].BookID) < bookKey(pinned[j].Provider, pinned[j].BookID)
+	})
+	return pinned, nil
+}
+
+// Books lists the cached books, most recently used first
+func (c *AudioCache) Books() ([]CachedBook, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.books()
+}
+
+func (c *AudioCache) books() ([]CachedBook, error) {
+	books, err := scanBooks(c.root)
+	if err != nil {
+		return nil, err
+	}
+	index, err := c.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range books {
+		record := index[bookKey(books[i].Provider, books[i].BookID)]
+		books[i].Pinned = record.Pinned
+		books[i].LastPlayed = record.LastPlayed
+	}
+
+	sort.SliceStable(books, func(i, j int) bool {
+		return books[i].LastUsed().After(books[j].LastUsed())
+	})
+	return books, nil
+}
+
+// hold keeps a book from being evicted until release is called
+func (c *AudioCache) hold(provider, bookID string) (release func()) {
+	key := bookKey(provider, bookID)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.held == nil {
+		c.held = make(map[string]int)
+	}
+	c.held[key]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			if c.held[key]--; c.held[key] == 0 {
+				delete(c.held, key)
+			}
+		})
+	}
+}
+
+// full reports whether the cache has reached its size limit
+func (c *AudioCache) full() (bool, error) {
+	if !c.enabled || c.maxBytes <= 0 {
+		return false, nil
+	}
+	size, err := c.Size()
+	if err != nil {
+		return false, err
+	}
+	return size >= c.maxBytes, nil
+}
+
+// Size is the total size of the cached audio in bytes
+func (c *AudioCache) Size() (int64, error) {
+	books, err := c.Books()
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, book := range books {
+		total += book.Bytes
+	}
+	return total, nil
+}
+
+// Enforce evicts the least recently used books until the cache fits within its
+// limit. Pinned books, books being prefetched and the book being played are never
+// evicted. It returns the books it removed.
+func (c *AudioCache) Enforce(playingProvider, playingBookID string) ([]CachedBook, error) {
+	if !c.enabled || c.maxBytes <= 0 {
+		return nil, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	books, err := c.books()
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for _, book := range books {
+		total += book.Bytes
+	}
+
+	// books is most recently used first, so evict from the end
+	var evicted []CachedBook
+	for i := len(books) - 1; i >= 0 && total > c.maxBytes; i-- {
+		book := books[i]
+		if book.Pinned || c.held[bookKey(book.Provider, book.BookID)] > 0 || (book.Provider == playingProvider && book.BookID == playingBookID) {
+			continue
+		}
+		if err := removeBook(c.root, book); err != nil {
+			return evicted, err
+		}
+		total -= book.Bytes
+		evicted = append(evicted, book)
+	}
+
+	if len(evicted) > 0 {
+		index, err := c.loadIndex()
+		if err != nil {
+			return evicted, err
+		}
+		for _, book := range evicted {
+			delete(index, bookKey(book.Provider, book.BookID))
+		}
+		if err := c.saveIndex(index); err != nil {
+			return evicted, err
+		}
+	}
+	return evicted, nil
+}
+
+// scanBooks totals up the audio under root/<provider>/<engine>/<book>
+func scanBooks(root string) ([]CachedBook, error) {
+	providers, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read audio cache: %w", err)
+	}
+
+	byKey := make(map[string]*CachedBook)
+	var keys []string
+	for _, provider := range providers {
+		if !provider.IsDir() {
+			continue
+		}
+		engines, err := os.ReadDir(filepath.Join(root, provider.Name()))
+		if err != nil {
+			continue
+		}
+
+		for _, engine := range engines {
+			if !engine.IsDir() {
+				continue
+			}
+			bookDirs, err := os.ReadDir(filepath.Join(root, provider.Name(), engine.Name()))
+			if err != nil {
+				continue
+			}
+
+			for _, bookDir := range bookDirs {
+				if !bookDir.IsDir() {
+					continue
+				}
+				key := bookKey(provider.Name(), bookDir.Name())
+				book, ok := byKey[key]
+				if !ok {
+					book = &CachedBook{Provider: provider.Name(), BookID: bookDir.Name()}
+					byKey[key] = book
+					keys = append(keys, key)
+				}
+				book.Engines = append(book.Engines, engine.Name())
+
+				dir := filepath.Join(root, provider.Name(), engine.Name(), bookDir.Name())
+				filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+					if err == nil && !info.IsDir() {
+						book.Files++
+						book.Bytes += info.Size()
+						if info.ModTime().After(book.Modified) {
+							book.Modified = info.ModTime()
+						}
+					}
+					return nil
+				})
+
+				if manifest, err := loadManifest(dir); err == nil {
+					for _, voice := range manifest.voices() {
+						book.Voices = append(book.Voices, engine.Name()+"/"+voice)
+					}
+				}
+			}
+		}
+	}
+
+	books := make([]CachedBook, len(keys))
+	for i, key := range keys {
+		books[i] = *byKey[key]
+	}
+	return books, nil
+}
+
+// Remove deletes the cached audio of one book, reporting whether there was any
+func (c *AudioCache) Remove(provider, bookID string) (CachedBook, bool, error) {
+	removed, err := c.removeWhere(func(book CachedBook) bool {
+		return book.Provider == provider && book.BookID == bookID
+	})
+	if err != nil || len(removed) == 0 {
+		return CachedBook{}, false, err
+	}
+	return removed[0], true, nil
+}
+
+// RemoveProvider deletes the cached audio of every book from a provider
+func (c *AudioCache) RemoveProvider(provider string) ([]CachedBook, error) {
+	return c.removeWhere(func(book CachedBook) bool {
+		return book.Provider == provider
+	})
+}
+
+// Prune deletes the audio of books that haven't been played or cached for longer than age.
+// Pinned books are kept.
+func (c *AudioCache) Prune(age time.Duration) ([]CachedBook, error) {
+	cutoff := time.Now().Add(-age)
+	return c.removeWhere(func(book CachedBook) bool {
+		return !book.Pinned && book.LastUsed().Before(cutoff)
+	})
+}
+
+// Clear deletes all cached audio and forgets which books were pinned
+func (c *AudioCache) Clear() error {
+	if _, err := c.removeWhere(func(CachedBook) bool { return true }); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(c.root, cacheIndexFile)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove audio cache index: %w", err)
+	}
+	return nil
+}
+
+// removeWhere deletes the books that match and drops them from the index
+func (c *AudioCache) removeWhere(match func(CachedBook) bool) ([]CachedBook, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	books, err := c.books()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []CachedBook
+	for _, book := range books {
+		if !match(book) {
+			continue
+		}
+		if err := removeBook(c.root, book); err != nil {
+			return removed, err
+		}
+		removed = append(removed, book)
+	}
+
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	index, err := c.loadIndex()
+	if err != nil {
+		return removed, err
+	}
+	for _, book := range removed {
+		delete(index, bookKey(book.Provider, book.BookID))
+	}
+	return removed, c.saveIndex(index)
+}
+
+// Verify checks every cached file against its book's manifest. With fix set, files
+// that fail are deleted so they are made again the next time the book is played.
+func (c *AudioCache) Verify(fix bool) ([]CacheProblem, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	books, err := c.books()
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []CacheProblem
+	for _, book := range books {
+		for _, engine := range book.Engines {
+			found, err := verifyBookDir(filepath.Join(c.root, book.Provider, engine, book.BookID), fix)
+			if err != nil {
+				return problems, err
+			}
+			for _, p := range found {
+				p.Book, p.Engine = book, engine
+				problems = append(problems, p)
+			}
+		}
+	}
+	return problems, nil
+}
+
+// verifyBookDir checks the audio in one engine's directory for a book
+func verifyBookDir(dir string, fix bool) ([]CacheProblem, error) {
+	manifest, err := loadManifest(dir)
+	if err != nil {
+		// Nothing in the directory can be vouched for
+		manifest.Chunks = map[string]ManifestChunk{}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	var problems []CacheProblem
+	listed := make(map[string]bool)
+	for file := range manifest.Chunks {
+		listed[file] = true
+		if err := manifest.check(file); err != nil {
+			problems = append(problems, CacheProblem{File: file, Problem: err.Error()})
+			if fix {
+				os.Remove(filepath.Join(dir, file))
+				os.Remove(marksPath(filepath.Join(dir, file)))
+				manifest.remove(file)
+			}
+		}
+	}
+
+	// Audio the manifest doesn't know about, e.g. from before there were manifests
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || listed[name] || name == manifestFile || name == manifestLockFile || strings.HasSuffix(name, ".marks.json") {
+			continue
+		}
+		problems = append(problems, CacheProblem{File: name, Problem: "isn't in the manifest"})
+		if fix {
+			os.Remove(filepath.Join(dir, name))
+			os.Remove(marksPath(filepath.Join(dir, name)))
+		}
+	}
+
+	if fix && len(problems) > 0 {
+		if err := manifest.save(); err != nil {
+			return problems, err
+		}
+	}
+	return problems, nil
+}
+
+// removeBook deletes a book's audio for every engine
+func removeBook(root string, book CachedBook) error {
+	for _, engine := range book.Engines {
+		if err := os.RemoveAll(filepath.Join(root, book.Provider, engine, book.BookID)); err != nil {
+			return fmt.Errorf("failed to remove cached audio for %s/%s: %w", book.Provider, book.BookID, err)
+		}
+	}
+	return nil
+}
+
+func bookKey(provider, bookID string) string {
+	return provider + "/" + bookID
+}
+
+// update changes the index under the lock and saves it
+func (c *AudioCache) update(change func(index map[string]cacheRecord)) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	index, err := c.loadIndex()
+	if err != nil {
+		return err
+	}
+	change(index)
+	return c.saveIndex(index)
+}
+
+// loadIndex reads the cache index, returning an empty one if there isn't one yet
+func (c *AudioCache) loadIndex() (map[string]cacheRecord, error) {
+	index := make(map[string]cacheRecord)
+
+	data, err := os.ReadFile(filepath.Join(c.root, cacheIndexFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return index, nil
+		}
+		return nil, fmt.Errorf("failed to read audio cache index: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse audio cache index: %w", err)
+	}
+	return index, nil
+}
+
+// saveIndex writes the cache index
+func (c *AudioCache) saveIndex(index map[string]cacheRecord) error {
+	if err := os.MkdirAll(c.root, 0755); err != nil {
+		return fmt.Errorf("failed to create audio cache directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode audio cache index: %w", err)
+	}
+
+	if err := writeFileAtomic(filepath.Join(c.root, cacheIndexFile), data); err != nil {
+		return fmt.Errorf("failed to write audio cache index: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package tts
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// chunkerVersion changes whenever chunk boundaries change, so cached audio made
+// with older boundaries is never reused for different text
+const chunkerVersion = "2"
+
+// textChunk is a piece of text to synthesize along with where it starts in the full text
+type textChunk struct {
+	Text   string
+	Offset int // byte offset of Text within the original string
+}
+
+// chunkText splits text into chunks no bigger than limit, as measured by size, e.g.
+// utf8.RuneCountInString. Chunks end at sentence or paragraph boundaries wherever
+// possible, so playback can stop cleanly between them.
+func chunkText(text string, limit int, size func(string) int) []textChunk {
+	var chunks []textChunk
+
+	start, end := -1, 0
+	flush := func() {
+		if start >= 0 {
+			chunks = appendTrimmed(chunks, text, start, end)
+		}
+		start = -1
+	}
+
+	for _, seg := range splitSentences(text) {
+		if size(text[seg.start:seg.end]) > limit {
+			// A single sentence longer than the limit has to be broken at word boundaries
+			flush()
+			for _, part := range splitLongSentence(text, seg.start, seg.end, limit, size) {
+				chunks = appendTrimmed(chunks, text, part.start, part.end)
+			}
+			continue
+		}
+
+		if start >= 0 && size(text[start:seg.end]) > limit {
+			flush()
+		}
+		if start < 0 {
+			start = seg.start
+		}
+		end = seg.end
+
+		// Prefer to finish a chunk at the end of a paragraph once it is reasonably full
+		if seg.paragraphEnd && size(text[start:end]) >= limit/2 {
+			flush()
+		}
+	}
+	flush()
+
+	return chunks
+}
+
+type span struct {
+	start, end   int
+	paragraphEnd bool
+}
+
+// splitSentences returns the byte spans of each sentence in text, including trailing whitespace
+func splitSentences(text string) []span {
+	var spans []span
+	start := 0
+
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		next := i + size
+
+		boundary := false
+		switch r {
+		case '.', '!', '?':
+			// Swallow closing quotes and brackets that belong to the sentence
+			for next < len(text) {
+				c, s := utf8.DecodeRuneInString(text[next:])
+				if !strings.ContainsRune(`"')]”’»`, c) {
+					break
+				}
+				next += s
+			}
+			if next >= len(text) {
+				boundary = true
+			} else if c, _ := utf8.DecodeRuneInString(text[next:]); unicode.IsSpace(c) {
+				boundary = true
+			}
+		case '\n':
+			boundary = isParagraphBreak(text, next)
+		}
+
+		if !boundary {
+			i = next
+			continue
+		}
+
+		// Include the whitespace following the sentence
+		for next < len(text) {
+			c, s := utf8.DecodeRuneInString(text[next:])
+			if !unicode.IsSpace(c) {
+				break
+			}
+			next += s
+		}
+
+		spans = append(spans, span{start: start, end: next, paragraphEnd: strings.Count(text[i:next], "\n") >= 2})
+		start = next
+		i = next
+	}
+
+	if start < len(text) {
+		spans = append(spans, span{start: start, end: len(text), paragraphEnd: true})
+	}
+
+	return spans
+}
+
+// isParagraphBreak reports whether the newline just before pos is followed by a blank line
+func isParagraphBreak(text string, pos int) bool {
+	rest := strings.TrimLeft(text[pos:], " \t\r")
+	return strings.HasPrefix(rest, "\n")
+}
+
+// splitLongSentence breaks text[start:end] into parts no bigger than limit at word
+// boundaries. Each part has at least one rune, even if that alone is over the limit.
+func splitLongSentence(text string, start, end, limit int, size func(string) int) []span {
+	// Where each rune starts, and the end, so parts are only ever cut between runes
+	var bounds []int
+	for i := start; i < end; {
+		bounds = append(bounds, i)
+		_, n := utf8.DecodeRuneInString(text[i:])
+		i += n
+	}
+	bounds = append(bounds, end)
+
+	var parts []span
+	for first := 0; first < len(bounds)-1; {
+		// The most runes that fit, found by bisecting as size only grows with the text
+		fit := sort.Search(len(bounds)-1-first, func(n int) bool {
+			return size(text[bounds[first]:bounds[first+n+1]]) > limit
+		})
+		last := first + max(fit, 1)
+
+		if last < len(bounds)-1 {
+			// Break after the last space that fits, if there is one
+			for i := last; i > first; i-- {
+				if r, _ := utf8.DecodeRuneInString(text[bounds[i-1]:]); unicode.IsSpace(r) {
+					last = i
+					break
+				}
+			}
+		}
+
+		parts = append(parts, span{start: bounds[first], end: bounds[last]})
+		first = last
+	}
+
+	return parts
+}
+
+// appendTrimmed adds text[start:end] without surrounding whitespace, skipping blank chunks
+func appendTrimmed(chunks []textChunk, text string, start, end int) []textChunk {
+	raw := text[start:end]
+	trimmed := strings.TrimLeftFunc(raw, unicode.IsSpace)
+	offset := start + len(raw) - len(trimmed)
+	trimmed = strings.TrimRightFunc(trimmed, unicode.IsSpace)
+
+	if trimmed == "" {
+		return chunks
+	}
+
+	return append(chunks, textChunk{Text: trimmed, Offset: offset})
+}
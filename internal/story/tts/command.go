@@ -0,0 +1,285 @@
+package tts
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// commandSpeedRange is the span of speeds passed to the command through {rate}
+var commandSpeedRange = Range{Min: 0.25, Max: 4.0}
+
+// Where a command writes its audio
+const (
+	commandOutputStdout = "stdout"
+	commandOutputFile   = "file"
+)
+
+// CommandEngine runs any local synthesizer (Festival, pico2wave, RHVoice, mimic...)
+// described in the config under tts.command, and plays its audio through the shared player
+type CommandEngine struct {
+	*player
+	spec  commandSpec
+	voice string
+	speed float64
+	mutex sync.RWMutex
+}
+
+// commandSpec is how to run the synthesizer, read from tts.command
+type commandSpec struct {
+	Name         string   // names the engine's cached audio; the program name unless set
+	Args         []string // argv with {text}, {voice}, {rate} and {out} placeholders
+	Output       string   // stdout or file
+	Format       string   // wav, mp3 or raw (16-bit little-endian mono PCM)
+	SampleRate   int      // of raw output
+	RateBase     float64  // {rate} at normal speed, e.g. 175 words per minute
+	DefaultVoice string   // {voice} when no voice has been chosen
+	VoicesArgs   []string // optional command printing one voice per line
+	ChunkLimit   int
+}
+
+// loadCommandSpec reads tts.command and checks it describes a command that can run
+func loadCommandSpec() (commandSpec, error) {
+	spec := commandSpec{
+		Name:         viper.GetString("tts.command.name"),
+		Args:         viper.GetStringSlice("tts.command.args"),
+		Output:       strings.ToLower(viper.GetString("tts.command.output")),
+		Format:       strings.ToLower(viper.GetString("tts.command.format")),
+		SampleRate:   viper.GetInt("tts.command.sample_rate"),
+		RateBase:     viper.GetFloat64("tts.command.rate_base"),
+		DefaultVoice: viper.GetString("tts.command.default_voice"),
+		VoicesArgs:   viper.GetStringSlice("tts.command.voices_args"),
+		ChunkLimit:   viper.GetInt("tts.command.chunk_limit"),
+	}
+
+	if len(spec.Args) == 0 {
+		return spec, fmt.Errorf("tts.command.args isn't set")
+	}
+	if _, err := exec.LookPath(spec.Args[0]); err != nil {
+		return spec, fmt.Errorf("command %s not found: %w", spec.Args[0], err)
+	}
+	if spec.Name == "" {
+		spec.Name = strings.TrimSuffix(filepath.Base(spec.Args[0]), filepath.Ext(spec.Args[0]))
+	}
+
+	switch spec.Output {
+	case commandOutputStdout:
+	case commandOutputFile:
+		if !spec.uses("{out}") {
+			return spec, fmt.Errorf("tts.command.args needs an {out} placeholder when output is file")
+		}
+	default:
+		return spec, fmt.Errorf("tts.command.output must be stdout or file, not '%s'", spec.Output)
+	}
+
+	switch spec.Format {
+	case "wav", "mp3":
+	case "raw":
+		if spec.SampleRate <= 0 {
+			return spec, fmt.Errorf("tts.command.sample_rate is needed for raw output")
+		}
+	default:
+		return spec, fmt.Errorf("tts.command.format must be wav, mp3 or raw, not '%s'", spec.Format)
+	}
+
+	if spec.RateBase <= 0 {
+		spec.RateBase = 175
+	}
+	if spec.ChunkLimit <= 0 {
+		spec.ChunkLimit = espeakChunkLimit
+	}
+	return spec, nil
+}
+
+// uses reports whether any argument contains the placeholder
+func (s commandSpec) uses(placeholder string) bool {
+	for _, arg := range s.Args {
+		if strings.Contains(arg, placeholder) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCommand reports whether a usable command engine is configured
+func hasCommand() bool {
+	_, err := loadCommandSpec()
+	return err == nil
+}
+
+// newCommandEngine creates an engine that runs the command configured under tts.command
+func newCommandEngine(config Config) (*CommandEngine, error) {
+	spec, err := loadCommandSpec()
+	if err != nil {
+		return nil, err
+	}
+
+	engine := &CommandEngine{
+		spec:  spec,
+		voice: config.Voice,
+		speed: config.Speed,
+	}
+	if engine.speed <= 0 {
+		engine.speed = 1.0
+	}
+	engine.player = newPlayer(engine, EngineTypeCommand.String()+"-"+spec.Name, OpenAudioCache(), config.Volume)
+
+	return engine, nil
+}
+
+// SynthesisParams are the voice and speed new audio is made with. The engine is named
+// after the command, so audio from different commands is never mixed up.
+func (c *CommandEngine) SynthesisParams() SynthesisParams {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	voice := c.voice
+	if voice == "" || voice == "default" {
+		voice = c.spec.DefaultVoice
+	}
+	return SynthesisParams{Engine: EngineTypeCommand.String() + "/" + c.spec.Name, Voice: voice, Speed: c.speed, ChunkerVersion: chunkerVersion}
+}
+
+func (c *CommandEngine) ChunkLimit() int {
+	return c.spec.ChunkLimit
+}
+
+// AudioFormat is the command's format; raw PCM is handed on as WAV
+func (c *CommandEngine) AudioFormat() string {
+	if c.spec.Format == "raw" {
+		return "wav"
+	}
+	return c.spec.Format
+}
+
+// Synthesize runs the command for one chunk. The text goes in as {text}, or on stdin
+// if the arguments have no {text}.
+func (c *CommandEngine) Synthesize(ctx context.Context, text string, params SynthesisParams) ([]byte, []WordMark, error) {
+	out := ""
+	if c.spec.Output == commandOutputFile {
+		ext := c.spec.Format
+		if ext == "raw" {
+			ext = "pcm"
+		}
+		f, err := os.CreateTemp("", "storynest-command-*."+ext)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create temporary audio file: %w", err)
+		}
+		f.Close()
+		defer os.Remove(f.Name())
+		out = f.Name()
+	}
+
+	replacer := strings.NewReplacer(
+		"{text}", text,
+		"{voice}", params.Voice,
+		"{rate}", strconv.Itoa(int(math.Round(c.spec.RateBase*params.Speed))),
+		"{out}", out,
+	)
+	args := make([]string, len(c.spec.Args))
+	for i, arg := range c.spec.Args {
+		args[i] = replacer.Replace(arg)
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	if !c.spec.uses("{text}") {
+		cmd.Stdin = strings.NewReader(text)
+	}
+
+	data, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return nil, nil, fmt.Errorf("%s failed: %w: %s", c.spec.Name, err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, nil, fmt.Errorf("%s failed: %w", c.spec.Name, err)
+	}
+	if out != "" {
+		if data, err = os.ReadFile(out); err != nil {
+			return nil, nil, fmt.Errorf("failed to read audio from %s: %w", c.spec.Name, err)
+		}
+	}
+	if len(data) == 0 {
+		return nil, nil, fmt.Errorf("%s produced no audio", c.spec.Name)
+	}
+
+	if c.spec.Format == "raw" {
+		data = pcmToWAV(data, c.spec.SampleRate, 1)
+	}
+	return data, nil, nil
+}
+
+func (c *CommandEngine) SetVoice(voice string) error {
+	c.mutex.Lock()
+	c.voice = voice
+	c.mutex.Unlock()
+	c.resynthesize()
+	return nil
+}
+
+func (c *CommandEngine) SetSpeed(speed float64) error {
+	if err := commandSpeedRange.check("speed", speed); err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	c.speed = speed
+	c.mutex.Unlock()
+	c.resynthesize()
+	return nil
+}
+
+func (c *CommandEngine) SpeedRange() Range {
+	return commandSpeedRange
+}
+
+func (c *CommandEngine) GetAvailableVoices() ([]string, error) {
+	voices, err := c.GetVoiceInfo()
+	if err != nil {
+		return nil, err
+	}
+	return voiceNames(voices), nil
+}
+
+// GetVoiceInfo runs tts.command.voices_args, which prints a voice per line: its name,
+// then optionally a description. Without it the default voice is the only one.
+func (c *CommandEngine) GetVoiceInfo() ([]VoiceInfo, error) {
+	if len(c.spec.VoicesArgs) == 0 {
+		if c.spec.DefaultVoice == "" {
+			return nil, nil
+		}
+		return []VoiceInfo{{Name: c.spec.DefaultVoice}}, nil
+	}
+
+	output, err := exec.Command(c.spec.VoicesArgs[0], c.spec.VoicesArgs[1:]...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s voices: %w", c.spec.Name, err)
+	}
+
+	voices := parseCommandVoices(string(output))
+	sortVoices(voices)
+	return voices, nil
+}
+
+// parseCommandVoices reads "name description..." lines, skipping blanks and # comments
+func parseCommandVoices(output string) []VoiceInfo {
+	var voices []VoiceInfo
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		voices = append(voices, VoiceInfo{
+			Name:        fields[0],
+			Description: strings.Join(fields[1:], " "),
+		})
+	}
+	return voices
+}
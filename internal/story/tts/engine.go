@@ -4,8 +4,6 @@ import (
 	"fmt"
 	"os"
 	"runtime"
-
-	"github.com/spf13/viper"
 )
 
 type EngineType string
@@ -17,42 +15,59 @@ const (
 	EngineTypeSAPI          EngineType = "sapi"         // Windows only
 	EngineTypeAVFoundation  EngineType = "avfoundation" // macOS only
 	EngineTypeGoogleClassic EngineType = "googleclassic"
-	EngineTypeAuto          EngineType = "auto" // Automatically choose best for platform
+	EngineTypePiper         EngineType = "piper"   // Local neural voices
+	EngineTypeCommand       EngineType = "command" // Any local synthesizer, described in the config
+	EngineTypeHTTP          EngineType = "http"    // OpenAI-compatible speech servers
+	EngineTypeAuto          EngineType = "auto"    // Automatically choose best for platform
 )
 
 func (e EngineType) String() string {
 	return string(e)
 }
 
+// ResolveEngineType returns the engine that NewEngine creates for engineType,
+// working out which one "auto" means on this platform
+func ResolveEngineType(engineType string) EngineType {
+	if engineType == "" || engineType == EngineTypeAuto.String() {
+		return getBestEngineForPlatform()
+	}
+	return EngineType(engineType)
+}
+
 // NewEngine creates a new TTS engine based on the provided config
-// todo: integrate this with viper config defaults
 func NewEngine(config Config) (Engine, error) {
-	// Handle auto-selection
-	if config.Type == EngineTypeAuto.String() {
-		config.Type = getBestEngineForPlatform().String()
-	}
+	config.Type = ResolveEngineType(config.Type).String()
 
 	switch config.Type {
 	case EngineTypeMock.String():
 		return NewMockTTSEngine(config), nil
 
 	case EngineTypeGoogleClassic.String():
-		cachePath := viper.GetString("tts.cache_path")
-		return newGoogleClassicTTSEngine(cachePath)
+		engine, err := newGoogleClassicTTSEngine(OpenAudioCache())
+		if err != nil {
+			return nil, err
+		}
+		if err := engine.configure(config); err != nil {
+			return nil, err
+		}
+		return engine, nil
 
 	case EngineTypeESpeak.String():
 		return newESpeakEngine(config)
 
+	case EngineTypePiper.String():
+		return newPiperEngine(config)
+
+	case EngineTypeCommand.String():
+		return newCommandEngine(config)
+
+	case EngineTypeHTTP.String():
+		return newHTTPEngine(config)
+
 	case EngineTypeSAPI.String():
-		if runtime.GOOS != "windows" {
-			return nil, fmt.Errorf("SAPI engine only supports Windows")
-		}
 		return newSAPIEngine(config)
 
 	case EngineTypeAVFoundation.String():
-		if runtime.GOOS != "darwin" {
-			return nil, fmt.Errorf("AVFoundation engine only supports macOS")
-		}
 		return newAVFoundationEngine(config)
 
 	default:
@@ -60,10 +75,6 @@ func NewEngine(config Config) (Engine, error) {
 	}
 }
 
-func newAVFoundationEngine(config Config) (Engine, error) {
-	panic("not implemented on windows")
-}
-
 // getBestEngineForPlatform returns the recommended engine for the current platform
 func getBestEngineForPlatform() EngineType {
 
@@ -71,6 +82,11 @@ func getBestEngineForPlatform() EngineType {
 		return EngineTypeGoogleClassic
 	}
 
+	// Piper sounds far more natural than the built-in voices and works offline
+	if hasPiper() {
+		return EngineTypePiper
+	}
+
 	switch runtime.GOOS {
 	case "windows":
 		return EngineTypeSAPI
@@ -92,6 +108,18 @@ func GetAvailableEngines() []EngineType {
 		engines = append(engines, EngineTypeGoogleClassic)
 	}
 
+	if hasPiper() {
+		engines = append(engines, EngineTypePiper)
+	}
+
+	if hasCommand() {
+		engines = append(engines, EngineTypeCommand)
+	}
+
+	if hasHTTP() {
+		engines = append(engines, EngineTypeHTTP)
+	}
+
 	switch runtime.GOOS {
 	case "windows":
 		engines = append(engines, EngineTypeSAPI)
@@ -2,6 +2,7 @@
 package tts
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"strconv"
@@ -9,19 +10,21 @@ import (
 	"sync"
 )
 
-// ESpeakEngine implements TTS using eSpeak/eSpeak-NG
+// ESpeakEngine synthesizes WAV with eSpeak/eSpeak-NG and plays it through the shared player
 type ESpeakEngine struct {
-	config  Config
-	cmd     *exec.Cmd
-	playing bool
-	paused  bool
-	mutex   sync.RWMutex
+	*player
+	path  string
+	voice string
+	speed float64
+	mutex sync.RWMutex
 }
 
-func (e *ESpeakEngine) SetBookContext(provider, bookID string) {
-	//TODO implement me
-	panic("implement me")
-}
+// espeakChunkLimit keeps each eSpeak process to a sentence or two, so the first
+// audio is ready quickly
+const espeakChunkLimit = 250
+
+// espeakSpeedRange is relative to eSpeak's default of 175 words per minute
+var espeakSpeedRange = Range{Min: 0.1, Max: 3.0}
 
 // newESpeakEngine creates a new eSpeak TTS engine
 func newESpeakEngine(config Config) (*ESpeakEngine, error) {
@@ -32,8 +35,14 @@ func newESpeakEngine(config Config) (*ESpeakEngine, error) {
 	}
 
 	engine := &ESpeakEngine{
-		config: config,
+		path:  espeakPath,
+		voice: config.Voice,
+		speed: config.Speed,
 	}
+	if engine.speed <= 0 {
+		engine.speed = 1.0
+	}
+	engine.player = newPlayer(engine, EngineTypeESpeak.String(), OpenAudioCache(), config.Volume)
 
 	// Test the installation
 	if err := engine.testInstallation(espeakPath); err != nil {
@@ -61,121 +70,44 @@ func (e *ESpeakEngine) testInstallation(espeakPath string) error {
 	return cmd.Run()
 }
 
-func (e *ESpeakEngine) Speak(text string) error {
-	e.mutex.Lock()
-	defer e.mutex.Unlock()
-
-	if e.playing {
-		return fmt.Errorf("already playing")
-	}
-
-	espeakPath, err := findESpeakExecutable()
-	if err != nil {
-		return err
-	}
-
-	// Build eSpeak command arguments
-	args := []string{}
-
-	// Set voice
-	if e.config.Voice != "" && e.config.Voice != "default" {
-		args = append(args, "-v", e.config.Voice)
-	}
-
-	// Set speed (words per minute, default is 175)
-	speed := int(175 * e.config.Speed)
-	args = append(args, "-s", strconv.Itoa(speed))
-
-	// Set volume (0-200, default is 100)
-	volume := int(100 * e.config.Volume)
-	args = append(args, "-a", strconv.Itoa(volume))
-
-	// Add text
-	args = append(args, text)
-
-	// Create command
-	e.cmd = exec.Command(espeakPath, args...)
-	e.playing = true
-	e.paused = false
-
-	// Start speaking in background
-	go func() {
-		defer func() {
-			e.mutex.Lock()
-			e.playing = false
-			e.paused = false
-			e.mutex.Unlock()
-		}()
-
-		if err := e.cmd.Run(); err != nil {
-			// Check if it was intentionally stopped
-			if e.cmd.ProcessState != nil && e.cmd.ProcessState.Exited() {
-				return
-			}
-			fmt.Printf("eSpeak error: %v\n", err)
-		}
-	}()
-
-	return nil
+// SynthesisParams are the voice and speed new audio is made with
+func (e *ESpeakEngine) SynthesisParams() SynthesisParams {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return SynthesisParams{Engine: EngineTypeESpeak.String(), Voice: e.voice, Speed: e.speed, ChunkerVersion: chunkerVersion}
 }
 
-func (e *ESpeakEngine) Stop() error {
-	e.mutex.Lock()
-	defer e.mutex.Unlock()
-
-	if e.cmd != nil && e.cmd.Process != nil {
-		if err := e.cmd.Process.Kill(); err != nil {
-			return err
-		}
-	}
-
-	e.playing = false
-	e.paused = false
-	return nil
+func (e *ESpeakEngine) ChunkLimit() int {
+	return espeakChunkLimit
 }
 
-func (e *ESpeakEngine) Pause() error {
-	e.mutex.Lock()
-	defer e.mutex.Unlock()
-
-	if !e.playing || e.paused {
-		return nil
-	}
-
-	if e.cmd != nil && e.cmd.Process != nil {
-		// eSpeak doesn't support pause/resume, so we stop the process
-		// This is a limitation of the eSpeak command-line interface
-		if err := e.pauseProcess(); err != nil {
-			return err
-		}
-		e.paused = true
-	}
-
-	return nil
+func (e *ESpeakEngine) AudioFormat() string {
+	return "wav"
 }
 
-func (e *ESpeakEngine) Resume() error {
-	e.mutex.Lock()
-	defer e.mutex.Unlock()
+// Synthesize renders a chunk to WAV with eSpeak's --stdout. The text goes in on stdin
+// so a chunk starting with a dash isn't taken for an option.
+func (e *ESpeakEngine) Synthesize(ctx context.Context, text string, params SynthesisParams) ([]byte, []WordMark, error) {
+	args := []string{"--stdout"}
 
-	if !e.paused {
-		return nil
+	// Set voice
+	if params.Voice != "" && params.Voice != "default" {
+		args = append(args, "-v", params.Voice)
 	}
 
-	if e.cmd != nil && e.cmd.Process != nil {
-		if err := e.resumeProcess(); err != nil {
-			return err
-		}
-		e.paused = false
-	}
+	// Set speed (words per minute, default is 175)
+	args = append(args, "-s", strconv.Itoa(int(175*params.Speed)))
 
-	return nil
+	cmd := exec.CommandContext(ctx, e.path, args...)
+	cmd.Stdin = strings.NewReader(text)
+	data, err := cmd.Output()
+	if err != nil {
+		return nil, nil, fmt.Errorf("eSpeak failed: %w", err)
+	}
+	return data, nil, nil
 }
 
 func (e *ESpeakEngine) SetVoice(voice string) error {
-	e.mutex.Lock()
-	defer e.mutex.Unlock()
-
 	// Validate voice exists
 	voices, err := e.GetAvailableVoices()
 	if err != nil {
@@ -194,47 +126,40 @@ func (e *ESpeakEngine) SetVoice(voice string) error {
 		return fmt.Errorf("voice '%s' not available", voice)
 	}
 
-	e.config.Voice = voice
+	e.mutex.Lock()
+	e.voice = voice
+	e.mutex.Unlock()
+	e.resynthesize()
 	return nil
 }
 
 func (e *ESpeakEngine) SetSpeed(speed float64) error {
-	e.mutex.Lock()
-	defer e.mutex.Unlock()
-
-	if speed <= 0 || speed > 3.0 {
-		return fmt.Errorf("speed must be between 0.1 and 3.0")
+	if err := espeakSpeedRange.check("speed", speed); err != nil {
+		return err
 	}
 
-	e.config.Speed = speed
-	return nil
-}
-
-func (e *ESpeakEngine) SetVolume(volume float64) error {
 	e.mutex.Lock()
-	defer e.mutex.Unlock()
-
-	if volume < 0 || volume > 2.0 {
-		return fmt.Errorf("volume must be between 0 and 2.0")
-	}
-
-	e.config.Volume = volume
+	e.speed = speed
+	e.mutex.Unlock()
+	e.resynthesize()
 	return nil
 }
 
-func (e *ESpeakEngine) IsPlaying() bool {
-	e.mutex.RLock()
-	defer e.mutex.RUnlock()
-	return e.playing && !e.paused
+// SpeedRange is the range of speeds eSpeak accepts, as a multiple of 175 words per minute
+func (e *ESpeakEngine) SpeedRange() Range {
+	return espeakSpeedRange
 }
 
-func (e *ESpeakEngine) IsPaused() bool {
-	e.mutex.RLock()
-	defer e.mutex.RUnlock()
-	return e.paused
+func (e *ESpeakEngine) GetAvailableVoices() ([]string, error) {
+	voices, err := e.GetVoiceInfo()
+	if err != nil {
+		return nil, err
+	}
+	return voiceNames(voices), nil
 }
 
-func (e *ESpeakEngine) GetAvailableVoices() ([]string, error) {
+// GetVoiceInfo describes the installed eSpeak voices from 'espeak --voices'
+func (e *ESpeakEngine) GetVoiceInfo() ([]VoiceInfo, error) {
 	espeakPath, err := findESpeakExecutable()
 	if err != nil {
 		return nil, err
@@ -243,92 +168,10 @@ func (e *ESpeakEngine) GetAvailableVoices() ([]string, error) {
 	cmd := exec.Command(espeakPath, "--voices")
 	output, err := cmd.Output()
 	if err != nil {
-		return nil, err
-	}
-
-	return parseESpeakVoices(string(output)), nil
-}
-
-func parseESpeakVoices(output string) []string {
-	lines := strings.Split(output, "\n")
-	voices := make([]string, 0)
-
-	for i, line := range lines {
-		// Skip header line
-		if i == 0 || strings.TrimSpace(line) == "" {
-			continue
-		}
-
-		// Parse voice line: Pty Language Age/Gender VoiceName          File          Other Languages
-		fields := strings.Fields(line)
-		if len(fields) >= 4 {
-			voices = append(voices, fields[3])
-		}
-	}
-
-	return voices
-}
-
-// chunkText splits text into smaller chunks suitable for TTS engines
-func (e *ESpeakEngine) chunkText(text string, maxWords int) []string {
-	words := strings.Fields(text)
-	if len(words) <= maxWords {
-		return []string{text}
-	}
-
-	var chunks []string
-	var currentChunk []string
-
-	for _, word := range words {
-		currentChunk = append(currentChunk, word)
-
-		// Check if we should split at a sentence boundary
-		if len(currentChunk) >= maxWords {
-			// Look for a good breaking point (sentence end)
-			chunkText := strings.Join(currentChunk, " ")
-			if breakPoint := e.findSentenceBreak(chunkText, len(currentChunk)*3/4); breakPoint > 0 {
-				// Split at sentence boundary
-				chunk := chunkText[:breakPoint]
-				remainder := strings.TrimSpace(chunkText[breakPoint:])
-
-				chunks = append(chunks, chunk)
-
-				// Start next chunk with remainder
-				if remainder != "" {
-					currentChunk = strings.Fields(remainder)
-				} else {
-					currentChunk = []string{}
-				}
-			} else {
-				// No good break point, split at word boundary
-				chunks = append(chunks, strings.Join(currentChunk, " "))
-				currentChunk = []string{}
-			}
-		}
-	}
-
-	// Add remaining words
-	if len(currentChunk) > 0 {
-		chunks = append(chunks, strings.Join(currentChunk, " "))
-	}
-
-	return chunks
-}
-
-// findSentenceBreak finds the best place to break text at a sentence boundary
-func (e *ESpeakEngine) findSentenceBreak(text string, minPos int) int {
-	// Look for sentence endings after the minimum position
-	sentenceEnders := []string{". ", "! ", "? ", ".\n", "!\n", "?\n"}
-
-	bestPos := -1
-	for _, ender := range sentenceEnders {
-		if pos := strings.Index(text[minPos:], ender); pos != -1 {
-			actualPos := minPos + pos + len(ender)
-			if bestPos == -1 || actualPos < bestPos {
-				bestPos = actualPos
-			}
-		}
+		return nil, fmt.Errorf("failed to list eSpeak voices: %w", err)
 	}
 
-	return bestPos
+	voices := parseESpeakVoiceInfo(string(output))
+	sortVoices(voices)
+	return voices, nil
 }
@@ -0,0 +1,233 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// EventType identifies what happened during playback
+type EventType int
+
+const (
+	EventStarted EventType = iota
+	EventChunkStarted
+	EventPaused
+	EventResumed
+	EventFinished
+	EventError
+	EventEngineSwitched
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventStarted:
+		return "started"
+	case EventChunkStarted:
+		return "chunk-started"
+	case EventPaused:
+		return "paused"
+	case EventResumed:
+		return "resumed"
+	case EventFinished:
+		return "finished"
+	case EventError:
+		return "error"
+	case EventEngineSwitched:
+		return "engine-switched"
+	default:
+		return "unknown"
+	}
+}
+
+// Event reports a change in playback. Every Speak produces Started, then ChunkStarted
+// for each chunk, and always ends with Finished (after Error if something went wrong).
+// EngineSwitched comes before the first chunk a fallback engine made, when the engine
+// in use failed part way through.
+type Event struct {
+	Type   EventType
+	Chunk  int    // index of the chunk for ChunkStarted
+	Chunks int    // total number of chunks for ChunkStarted
+	Offset int    // byte offset of the chunk in the text passed to Speak
+	Err    error  // set for Error, and for EngineSwitched with why the previous engine failed
+	Engine string // engine now speaking, for EngineSwitched
+}
+
+// eventBuffer is how many events a slow subscriber can fall behind before ChunkStarted
+// events are dropped. Every other event is always delivered.
+const eventBuffer = 64
+
+// eventHub fans playback events out to subscribers. Each Speak begins a new run so
+// that events from a playback that has already finished are never delivered late.
+type eventHub struct {
+	mu    sync.Mutex
+	subs  map[*subscription]struct{}
+	run   int
+	ended bool
+}
+
+// subscription queues events for one subscriber and hands them over in order, so
+// sending never waits on a subscriber that has fallen behind
+type subscription struct {
+	ch    chan Event
+	mu    sync.Mutex
+	queue []Event
+	wake  chan struct{}
+	done  chan struct{}
+}
+
+// Subscribe returns a channel of playback events and a func to stop receiving them
+func (h *eventHub) Subscribe() (<-chan Event, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.subs == nil {
+		h.subs = make(map[*subscription]struct{})
+	}
+	sub := &subscription{
+		ch:   make(chan Event),
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	h.subs[sub] = struct{}{}
+	go sub.deliver()
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs, sub)
+			h.mu.Unlock()
+			close(sub.done)
+		})
+	}
+}
+
+// push queues ev, dropping it if it is only a ChunkStarted and the subscriber is too far behind
+func (s *subscription) push(ev Event) {
+	s.mu.Lock()
+	if ev.Type == EventChunkStarted && len(s.queue) >= eventBuffer {
+		s.mu.Unlock()
+		return
+	}
+	s.queue = append(s.queue, ev)
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliver hands queued events to the subscriber until it unsubscribes
+func (s *subscription) deliver() {
+	for {
+		s.mu.Lock()
+		if len(s.queue) == 0 {
+			s.mu.Unlock()
+			select {
+			case <-s.wake:
+				continue
+			case <-s.done:
+				return
+			}
+		}
+		ev := s.queue[0]
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+
+		select {
+		case s.ch <- ev:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// start begins a new run, emits Started and returns the run to tag later events with
+func (h *eventHub) start() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.run++
+	h.ended = false
+	h.send(Event{Type: EventStarted})
+	return h.run
+}
+
+// emit sends an event for the given run, dropping it if that run is over
+func (h *eventHub) emit(run int, ev Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if run != h.run || h.ended {
+		return
+	}
+	h.send(ev)
+}
+
+// emitCurrent sends an event for whatever run is in progress
+func (h *eventHub) emitCurrent(ev Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.ended || h.run == 0 {
+		return
+	}
+	h.send(ev)
+}
+
+// chunk emits ChunkStarted for a chunk of the run
+func (h *eventHub) chunk(run, index int, chunks []textChunk) {
+	if index < 0 || index >= len(chunks) {
+		return
+	}
+	h.emit(run, Event{Type: EventChunkStarted, Chunk: index, Chunks: len(chunks), Offset: chunks[index].Offset})
+}
+
+// finish ends a run with Finished, preceded by Error if err is set. Only the first call counts.
+func (h *eventHub) finish(run int, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if run != h.run || h.ended {
+		return
+	}
+	if err != nil {
+		h.send(Event{Type: EventError, Err: err})
+	}
+	h.send(Event{Type: EventFinished})
+	h.ended = true
+}
+
+// finishCurrent ends whatever run is in progress, e.g. when playback is stopped
+func (h *eventHub) finishCurrent() {
+	h.mu.Lock()
+	run := h.run
+	h.mu.Unlock()
+
+	h.finish(run, nil)
+}
+
+// current returns the run in progress
+func (h *eventHub) current() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.run
+}
+
+// send queues ev for every subscriber without blocking; the caller holds h.mu
+func (h *eventHub) send(ev Event) {
+	for sub := range h.subs {
+		sub.push(ev)
+	}
+}
+
+// contextError turns the reason a context ended into a playback error. Plain
+// cancellation is how callers stop playback, so only a missed deadline is reported.
+func contextError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return nil
+}
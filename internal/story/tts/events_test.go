@@ -0,0 +1,41 @@
+package tts
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSlowSubscriberStillGetsFinished(t *testing.T) {
+	var h eventHub
+	events, unsubscribe := h.Subscribe()
+	defer unsubscribe()
+
+	chunks := make([]textChunk, 3*eventBuffer)
+	run := h.start()
+	for i := range chunks {
+		h.chunk(run, i, chunks)
+	}
+	h.finish(run, errors.New("the last part failed"))
+
+	var got []EventType
+	timeout := time.After(5 * time.Second)
+	for len(got) == 0 || got[len(got)-1] != EventFinished {
+		select {
+		case ev := <-events:
+			got = append(got, ev.Type)
+		case <-timeout:
+			t.Fatalf("Finished never arrived after %d events", len(got))
+		}
+	}
+
+	if got[0] != EventStarted {
+		t.Errorf("first event is %v, want started", got[0])
+	}
+	if got[len(got)-2] != EventError {
+		t.Errorf("the event before finished is %v, want error", got[len(got)-2])
+	}
+	if n := len(got) - 3; n > eventBuffer {
+		t.Errorf("%d chunk events were queued, want at most %d", n, eventBuffer)
+	}
+}
@@ -0,0 +1,83 @@
+package tts
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/faiface/beep/effects"
+)
+
+// fadeStep is how often a volume ramp updates the level
+const fadeStep = 100 * time.Millisecond
+
+// volumeFader moves a volume multiplier towards a target in small steps.
+// Starting a new fade cancels any fade that is still running.
+type volumeFader struct {
+	mu   sync.Mutex
+	stop chan struct{}
+	gen  int // counts fades and cancels, so a step already under way can tell it is stale
+}
+
+// start ramps from one level to another over the given duration, calling set for each
+// step. A step may already be waiting on a lock when the fade is cancelled, so set is
+// given current, which it checks under the lock it writes the level with.
+func (f *volumeFader) start(from, to float64, over time.Duration, set func(level float64, current func() bool)) {
+	f.cancel()
+
+	f.mu.Lock()
+	stop := make(chan struct{})
+	f.stop = stop
+	f.gen++
+	gen := f.gen
+	f.mu.Unlock()
+
+	current := func() bool {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return f.gen == gen
+	}
+
+	steps := int(over / fadeStep)
+	if steps < 1 {
+		set(to, current)
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(fadeStep)
+		defer ticker.Stop()
+
+		for i := 1; i <= steps; i++ {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				set(from+(to-from)*float64(i)/float64(steps), current)
+			}
+		}
+	}()
+}
+
+// cancel stops any running fade, leaving the level where it is
+func (f *volumeFader) cancel() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.gen++
+	if f.stop != nil {
+		close(f.stop)
+		f.stop = nil
+	}
+}
+
+// applyVolume sets a beep volume effect to a linear level where 1.0 is unchanged
+func applyVolume(v *effects.Volume, level float64) {
+	if level <= 0 {
+		v.Silent = true
+		return
+	}
+	v.Silent = false
+	v.Base = 2
+	v.Volume = math.Log2(level)
+}
@@ -0,0 +1,117 @@
+package tts
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// fakeSynth is a Synthesizer for tests. Left empty it makes 0.1s of silent WAV for
+// each chunk of up to 100 runes; each test sets only what it needs.
+type fakeSynth struct {
+	engine string // defaults to "fake"
+	limit  int    // ChunkLimit, 100 if not set
+	pcm    int    // bytes of silent 24kHz PCM in each chunk, 4800 if not set
+	audio  []byte // returned instead of the silence, e.g. a WAV written to a pipe
+	err    error  // Synthesize fails with it
+	// stream, if set, makes the synthesizer stream each chunk as the PCM it returns
+	stream func(text string) (io.ReadCloser, error)
+
+	mu    sync.Mutex
+	speed float64
+	calls int                        // chunks Synthesize and SynthesizeStream were asked for
+	made  map[string]SynthesisParams // the settings each text was last made with
+}
+
+// player plays the fake's audio, streaming it if the fake streams
+func (f *fakeSynth) player(cache *AudioCache) *player {
+	var synth Synthesizer = f
+	if f.stream != nil {
+		synth = streamingFake{f}
+	}
+	return newPlayer(synth, f.SynthesisParams().Engine, cache, 1)
+}
+
+func (f *fakeSynth) SynthesisParams() SynthesisParams {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	engine := f.engine
+	if engine == "" {
+		engine = "fake"
+	}
+	return SynthesisParams{Engine: engine, Speed: f.speed, ChunkerVersion: chunkerVersion}
+}
+
+func (f *fakeSynth) ChunkLimit() int {
+	if f.limit == 0 {
+		return 100
+	}
+	return f.limit
+}
+
+func (f *fakeSynth) AudioFormat() string {
+	return "wav"
+}
+
+func (f *fakeSynth) Synthesize(ctx context.Context, text string, params SynthesisParams) ([]byte, []WordMark, error) {
+	f.record(text, params)
+	if f.err != nil {
+		return nil, nil, f.err
+	}
+	if f.audio != nil {
+		return append([]byte(nil), f.audio...), nil, nil
+	}
+	pcm := f.pcm
+	if pcm == 0 {
+		pcm = 4800
+	}
+	return pcmToWAV(make([]byte, pcm), 24000, 1), nil, nil
+}
+
+func (f *fakeSynth) record(text string, params SynthesisParams) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.made == nil {
+		f.made = make(map[string]SynthesisParams)
+	}
+	f.made[text] = params
+}
+
+func (f *fakeSynth) setSpeed(speed float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.speed = speed
+}
+
+// count is how many chunks the fake has been asked to make
+func (f *fakeSynth) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+// madeWith returns the settings text was last made with
+func (f *fakeSynth) madeWith(text string) SynthesisParams {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.made[text]
+}
+
+// texts is how many different texts the fake has made
+func (f *fakeSynth) texts() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.made)
+}
+
+// streamingFake is a fakeSynth that streams its audio
+type streamingFake struct {
+	*fakeSynth
+}
+
+func (s streamingFake) SynthesizeStream(ctx context.Context, text string, params SynthesisParams) (io.ReadCloser, int, error) {
+	s.record(text, params)
+	r, err := s.stream(text)
+	return r, 24000, err
+}
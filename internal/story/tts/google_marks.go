@@ -0,0 +1,84 @@
+package tts
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	texttospeechpb "google.golang.org/genproto/googleapis/cloud/texttospeech/v1"
+	beta "google.golang.org/genproto/googleapis/cloud/texttospeech/v1beta1"
+)
+
+// supportsMarks reports whether a Google voice accepts SSML <mark> tags.
+// Chirp voices ignore SSML, so they only get sentence-level timing.
+func supportsMarks(voice string) bool {
+	return !strings.Contains(strings.ToLower(voice), "chirp")
+}
+
+// ssmlWithMarks wraps text in SSML with a <mark> before every word, returning
+// the byte offset of each marked word within text
+func ssmlWithMarks(text string) (string, []int) {
+	var b strings.Builder
+	var offsets []int
+
+	b.WriteString("<speak>")
+	inWord := false
+	for i, r := range text {
+		if unicode.IsSpace(r) {
+			inWord = false
+		} else if !inWord {
+			inWord = true
+			fmt.Fprintf(&b, `<mark name="w%d"/>`, len(offsets))
+			offsets = append(offsets, i)
+		}
+		b.WriteString(html.EscapeString(string(r)))
+	}
+	b.WriteString("</speak>")
+
+	return b.String(), offsets
+}
+
+// synthesizeWithMarks synthesizes one chunk through the v1beta1 API, which is the only
+// version that reports timepoints, and returns the audio with the time of every word
+func (g *GoogleClassicTTSEngine) synthesizeWithMarks(ctx context.Context, text string, voice *texttospeechpb.VoiceSelectionParams, audio *texttospeechpb.AudioConfig) ([]byte, []WordMark, error) {
+	ssml, offsets := ssmlWithMarks(text)
+
+	req := &beta.SynthesizeSpeechRequest{
+		Input: &beta.SynthesisInput{
+			InputSource: &beta.SynthesisInput_Ssml{Ssml: ssml},
+		},
+		Voice: &beta.VoiceSelectionParams{
+			LanguageCode: voice.LanguageCode,
+			Name:         voice.Name,
+		},
+		AudioConfig: &beta.AudioConfig{
+			AudioEncoding: beta.AudioEncoding_MP3,
+			SpeakingRate:  audio.SpeakingRate,
+			VolumeGainDb:  audio.VolumeGainDb,
+		},
+		EnableTimePointing: []beta.SynthesizeSpeechRequest_TimepointType{beta.SynthesizeSpeechRequest_SSML_MARK},
+	}
+
+	resp, err := beta.NewTextToSpeechClient(g.client.Connection()).SynthesizeSpeech(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	marks := make([]WordMark, 0, len(resp.GetTimepoints()))
+	for _, tp := range resp.GetTimepoints() {
+		index, err := strconv.Atoi(strings.TrimPrefix(tp.GetMarkName(), "w"))
+		if err != nil || index < 0 || index >= len(offsets) {
+			continue
+		}
+		marks = append(marks, WordMark{
+			Offset: offsets[index],
+			Time:   time.Duration(tp.GetTimeSeconds() * float64(time.Second)),
+		})
+	}
+
+	return resp.GetAudioContent(), marks, nil
+}
@@ -0,0 +1,48 @@
+package tts
+
+import (
+	"strings"
+	"testing"
+)
+
+// googleRequestLimit is the most input the API accepts in one request
+const googleRequestLimit = 5000
+
+func TestSSMLWithMarksFitsRequestLimit(t *testing.T) {
+	g := &GoogleClassicTTSEngine{voice: "en-GB-Standard-A"}
+
+	texts := map[string]string{
+		"story":             strings.Repeat("Once upon a time, the bears & Goldilocks said \"hello\" to the <very> small chair. ", 100),
+		"short words":       strings.Repeat("a & b < c ", 1000),
+		"one long sentence": strings.Repeat("and then ", 1500),
+		"cjk and emoji":     strings.Repeat("むかしむかし、あるところに🐻が三びきいました。", 100),
+	}
+	for name, text := range texts {
+		chunks := chunkText(text, g.ChunkLimit(), g.ChunkSize)
+		if len(chunks) < 2 {
+			t.Errorf("%s: got %d chunks, want the text split", name, len(chunks))
+		}
+		for i, chunk := range chunks {
+			ssml, _ := ssmlWithMarks(chunk.Text)
+			if len(ssml) >= googleRequestLimit {
+				t.Errorf("%s: chunk %d is %d bytes of SSML, want under %d", name, i, len(ssml), googleRequestLimit)
+			}
+		}
+	}
+}
+
+func TestGoogleChunksWithoutMarksCountBytes(t *testing.T) {
+	g := &GoogleClassicTTSEngine{voice: "en-GB-Chirp3-HD-Umbriel"}
+
+	texts := map[string]string{
+		"story":         strings.Repeat("Once upon a time there were three bears. ", 100),
+		"cjk and emoji": strings.Repeat("むかしむかし、あるところに🐻が三びきいました。", 100),
+	}
+	for name, text := range texts {
+		for i, chunk := range chunkText(text, g.ChunkLimit(), g.ChunkSize) {
+			if n := len(chunk.Text); n > googleChunkLimit {
+				t.Errorf("%s: chunk %d is %d bytes, want at most %d", name, i, n, googleChunkLimit)
+			}
+		}
+	}
+}
@@ -2,386 +2,233 @@ package tts
 
 import (
 	"context"
-	"crypto/md5"
 	"fmt"
-	"io"
 	"os"
-	"path/filepath"
 	"strings"
 	"sync"
-	"time"
 
 	"cloud.google.com/go/texttospeech/apiv1"
-	"github.com/faiface/beep"
-	"github.com/faiface/beep/mp3"
-	"github.com/faiface/beep/speaker"
 	texttospeechpb "google.golang.org/genproto/googleapis/cloud/texttospeech/v1"
 )
 
+// googleChunkLimit keeps each request well under the API's 5000 byte limit and
+// chunks short enough that playback can stop at a nearby paragraph or sentence.
+// It is in bytes like the API's limit, since a rune of CJK text or an emoji takes up to four.
+const googleChunkLimit = 1500
+
+// googleSSMLLimit is the most SSML to send in one request. A mark before every word
+// makes the SSML several times the size of the text, so it is measured instead.
+const googleSSMLLimit = 4500
+
+// googleSpeedRange is the speaking rate range the API accepts
+var googleSpeedRange = Range{Min: 0.25, Max: 4.0}
+
+// GoogleClassicTTSEngine synthesizes MP3 with Google Cloud Text-to-Speech and plays it
+// through the shared player
 type GoogleClassicTTSEngine struct {
-	client          *texttospeech.Client
-	ctx             context.Context
-	voice           string
-	speed           float64
-	volume          float64
-	isPlaying       bool
-	ctrl            *beep.Ctrl
-	format          beep.Format
-	streamer        beep.StreamSeekCloser
-	done            chan bool
-	mu              sync.Mutex
-	cacheRootDir    string
-	currentProvider string
-	currentBookID   string
+	*player
+	client *texttospeech.Client
+	voice  string
+	speed  float64
+	mu     sync.Mutex
 }
 
-func newGoogleClassicTTSEngine(cacheDir string) (*GoogleClassicTTSEngine, error) {
+func newGoogleClassicTTSEngine(cache *AudioCache) (*GoogleClassicTTSEngine, error) {
 	ctx := context.Background()
 	client, err := texttospeech.NewClient(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create TTS client: %w", err)
 	}
 
-	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+	if err := os.MkdirAll(cache.Root(), 0755); err != nil {
 		return nil, fmt.Errorf("failed to create cache dir: %w", err)
 	}
 
-	return &GoogleClassicTTSEngine{
-		client:       client,
-		ctx:          ctx,
-		voice:        "en-GB-Chirp3-HD-Umbriel", //some random default
-		speed:        1.0,
-		volume:       1.0,
-		cacheRootDir: cacheDir,
-	}, nil
+	g := &GoogleClassicTTSEngine{
+		client: client,
+		voice:  "en-GB-Chirp3-HD-Umbriel", //some random default
+		speed:  1.0,
+	}
+	g.player = newPlayer(g, "google_classic", cache, 1.0)
+	return g, nil
 }
 
-// SetBookContext sets the current provider and book ID for caching purposes
-// This should be called before Speak() to ensure proper cache organization
-func (g *GoogleClassicTTSEngine) SetBookContext(provider, bookID string) {
+// configure applies the voice, speed and volume from config, keeping the defaults for anything unset
+func (g *GoogleClassicTTSEngine) configure(config Config) error {
+	if config.Voice != "" && config.Voice != "default" {
+		if err := g.SetVoice(config.Voice); err != nil {
+			return err
+		}
+	}
+	if config.Speed > 0 {
+		if err := g.SetSpeed(config.Speed); err != nil {
+			return err
+		}
+	}
+	if config.Volume > 0 {
+		if err := g.SetVolume(config.Volume); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SynthesisParams are the settings new audio is made with. Volume is left to the player.
+func (g *GoogleClassicTTSEngine) SynthesisParams() SynthesisParams {
 	g.mu.Lock()
 	defer g.mu.Unlock()
-	g.currentProvider = provider
-	g.currentBookID = bookID
-}
 
-// getCacheDirectory returns the hierarchical cache directory for the current book
-func (g *GoogleClassicTTSEngine) getCacheDirectory() string {
-	if g.currentProvider == "" || g.currentBookID == "" {
-		// Fallback to old behavior if context not set
-		return g.cacheRootDir
+	params := SynthesisParams{Engine: EngineTypeGoogleClassic.String(), Voice: g.voice, ChunkerVersion: chunkerVersion}
+	if !strings.Contains(strings.ToLower(g.voice), "chirp") {
+		params.Speed = g.speed
 	}
+	return params
+}
 
-	return filepath.Join(g.cacheRootDir, g.currentProvider, "google_classic", g.currentBookID)
+// ChunkLimit keeps requests under the API's size limit
+func (g *GoogleClassicTTSEngine) ChunkLimit() int {
+	if g.marks() {
+		return googleSSMLLimit
+	}
+	return googleChunkLimit
 }
 
-// getCacheFilePrefix returns the prefix for cache files (bookID if available)
-func (g *GoogleClassicTTSEngine) getCacheFilePrefix() string {
-	if g.currentBookID != "" {
-		return g.currentBookID
+// ChunkSize is the size in bytes of the SSML text is sent as when the voice takes marks, and of the text otherwise
+func (g *GoogleClassicTTSEngine) ChunkSize(text string) int {
+	if g.marks() {
+		ssml, _ := ssmlWithMarks(text)
+		return len(ssml)
 	}
-	// Fallback to hash-based naming
-	return "audio"
+	return len(text)
 }
 
-func (g *GoogleClassicTTSEngine) Speak(text string) error {
+// marks reports whether the current voice is sent SSML with a mark before every word
+func (g *GoogleClassicTTSEngine) marks() bool {
 	g.mu.Lock()
 	defer g.mu.Unlock()
+	return supportsMarks(g.voice)
+}
 
-	// Get the cache directory for this book
-	cacheDir := g.getCacheDirectory()
-	if err := os.MkdirAll(cacheDir, 0755); err != nil {
-		return fmt.Errorf("failed to create cache directory %s: %w", cacheDir, err)
-	}
+// AudioFormat is MP3, which keeps the cache small
+func (g *GoogleClassicTTSEngine) AudioFormat() string {
+	return "mp3"
+}
 
+// Synthesize makes the MP3 for one chunk. Voices that understand SSML marks also
+// give us word timings for captions.
+func (g *GoogleClassicTTSEngine) Synthesize(ctx context.Context, text string, params SynthesisParams) ([]byte, []WordMark, error) {
 	audioCfg := &texttospeechpb.AudioConfig{
 		AudioEncoding: texttospeechpb.AudioEncoding_MP3,
+		SpeakingRate:  params.Speed, // left unset for Chirp voices, which often don't support it
 	}
-
-	// Chirp voices often don't support speakingRate/pitch/SSML — skip them
-	if !strings.Contains(strings.ToLower(g.voice), "chirp") {
-		audioCfg.SpeakingRate = g.speed  // supported generally. See docs.
-		audioCfg.VolumeGainDb = g.volume // interpret as dB gain (or map to dB as you prefer)
-		// audioCfg.Pitch = ...   // set only if voice supports it
+	voice := &texttospeechpb.VoiceSelectionParams{
+		LanguageCode: googleLanguageCode(params.Voice),
+		Name:         params.Voice,
 	}
 
-	// Create a unique identifier for this specific text + voice combination
-	contentHash := fmt.Sprintf("%x", md5Sum(text+g.voice))[:8] // Use first 8 chars of hash
-
-	// File prefix based on current context
-	filePrefix := g.getCacheFilePrefix()
-
-	chunks := splitIntoChunks(text, 4800) // a little under 5000 to be safe
-
-	// Generate MP3 files if not cached
-	allChunksExist := true
-	for i := 0; i < len(chunks); i++ {
-		chunkFileName := fmt.Sprintf("%s_%s_%d.mp3", filePrefix, contentHash, i)
-		chunkPath := filepath.Join(cacheDir, chunkFileName)
-		if _, err := os.Stat(chunkPath); os.IsNotExist(err) {
-			allChunksExist = false
-			break
-		}
+	if supportsMarks(voice.Name) {
+		return g.synthesizeWithMarks(ctx, text, voice, audioCfg)
 	}
+	audio, err := g.synthesize(ctx, text, voice, audioCfg)
+	return audio, nil, err
+}
 
-	if !allChunksExist {
-		fmt.Printf("Generating audio for %s (provider: %s, book: %s)\n",
-			filePrefix, g.currentProvider, g.currentBookID)
-
-		for chunkIndex, chunk := range chunks {
-
-			req := &texttospeechpb.SynthesizeSpeechRequest{
-				Input: &texttospeechpb.SynthesisInput{
-					InputSource: &texttospeechpb.SynthesisInput_Text{Text: chunk},
-				},
-				Voice: &texttospeechpb.VoiceSelectionParams{
-					LanguageCode: "en-US",
-					Name:         "en-US-Chirp3-HD-Charon",
-				},
-				AudioConfig: &texttospeechpb.AudioConfig{
-					AudioEncoding: texttospeechpb.AudioEncoding_MP3,
-				},
-			}
-			resp, err := g.client.SynthesizeSpeech(g.ctx, req)
-			if err != nil {
-				return fmt.Errorf("failed to synthesize chunk %d: %w", chunkIndex, err)
-			}
-
-			chunkFileName := fmt.Sprintf("%s_%s_%d.mp3", filePrefix, contentHash, chunkIndex)
-			chunkPath := filepath.Join(cacheDir, chunkFileName)
-
-			if err := os.WriteFile(chunkPath, resp.AudioContent, 0644); err != nil {
-				return fmt.Errorf("failed to write MP3 chunk %d to %s: %w", chunkIndex, chunkPath, err)
-			}
-
-			fmt.Printf("Cached audio chunk %d/%d to: %s\n", chunkIndex+1, len(chunks), chunkPath)
-		}
-	} else {
-		fmt.Printf("Using cached audio for %s (provider: %s, book: %s)\n",
-			filePrefix, g.currentProvider, g.currentBookID)
+// googleLanguageCode takes the language from a voice name like "en-GB-Chirp3-HD-Umbriel"
+func googleLanguageCode(voice string) string {
+	parts := strings.SplitN(voice, "-", 3)
+	if len(parts) < 3 {
+		return "en-US"
 	}
+	return parts[0] + "-" + parts[1]
+}
 
-	// Play cached files
-	for i := 0; i < len(chunks); i++ {
-		
-		chunkFileName := fmt.Sprintf("%s_%s_%d.mp3", filePrefix, contentHash, i)
-		chunkPath := filepath.Join(cacheDir, chunkFileName)
-
-		f, err := os.Open(chunkPath)
-		if err != nil {
-			return fmt.Errorf("failed to open cached MP3 %s: %w", chunkPath, err)
-		}
-
-		streamer, format, err := mp3.Decode(f)
-		if err != nil {
-			f.Close()
-			return fmt.Errorf("failed to decode MP3 %s: %w", chunkPath, err)
-		}
-		g.streamer = streamer
-		g.format = format
-		g.ctrl = &beep.Ctrl{Streamer: streamer, Paused: false}
-		g.done = make(chan bool)
-
-		err = speaker.Init(format.SampleRate, format.SampleRate.N(time.Second/10))
-		if err != nil {
-			streamer.Close()
-			f.Close()
-			return err
-		}
-		speaker.Play(beep.Seq(g.ctrl, beep.Callback(func() {
-			g.isPlaying = false
-			g.done <- true
-		})))
-
-		g.isPlaying = true
+// synthesize makes the audio for one chunk of plain text
+func (g *GoogleClassicTTSEngine) synthesize(ctx context.Context, text string, voice *texttospeechpb.VoiceSelectionParams, audio *texttospeechpb.AudioConfig) ([]byte, error) {
+	req := &texttospeechpb.SynthesizeSpeechRequest{
+		Input: &texttospeechpb.SynthesisInput{
+			InputSource: &texttospeechpb.SynthesisInput_Text{Text: text},
+		},
+		Voice:       voice,
+		AudioConfig: audio,
 	}
-	return nil
+	resp, err := g.client.SynthesizeSpeech(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.AudioContent, nil
 }
 
 func (g *GoogleClassicTTSEngine) SetVoice(voice string) error {
+	g.mu.Lock()
 	g.voice = voice
+	g.mu.Unlock()
+	g.resynthesize()
 	return nil
 }
 
 func (g *GoogleClassicTTSEngine) SetSpeed(speed float64) error {
+	if err := googleSpeedRange.check("speed", speed); err != nil {
+		return err
+	}
+	g.mu.Lock()
 	g.speed = speed
+	g.mu.Unlock()
+	g.resynthesize()
 	return nil
 }
 
-func (g *GoogleClassicTTSEngine) SetVolume(volume float64) error {
-	g.volume = volume
-	return nil
+// SpeedRange is the speaking rate range the API accepts
+func (g *GoogleClassicTTSEngine) SpeedRange() Range {
+	return googleSpeedRange
 }
 
-func (g *GoogleClassicTTSEngine) Stop() error {
-	if g.streamer != nil {
-		g.streamer.Close()
-	}
-	g.isPlaying = false
-	return nil
-}
+// Capabilities depend on the voice: voices that take SSML marks report word timings,
+// and Chirp voices read at one speed
+func (g *GoogleClassicTTSEngine) Capabilities() Capabilities {
+	caps := g.player.Capabilities()
 
-func (g *GoogleClassicTTSEngine) Pause() error {
-	if g.ctrl != nil {
-		speaker.Lock()
-		g.ctrl.Paused = true
-		speaker.Unlock()
-	}
-	return nil
-}
+	g.mu.Lock()
+	voice := g.voice
+	g.mu.Unlock()
 
-func (g *GoogleClassicTTSEngine) Resume() error {
-	if g.ctrl != nil {
-		speaker.Lock()
-		g.ctrl.Paused = false
-		speaker.Unlock()
+	marks := supportsMarks(voice)
+	caps.SSML, caps.WordTimings = marks, marks
+	if !marks {
+		caps.Speed = Range{Min: 1, Max: 1}
 	}
-	return nil
-}
-
-func (g *GoogleClassicTTSEngine) IsPlaying() bool {
-	return g.isPlaying
+	return caps
 }
 
 func (g *GoogleClassicTTSEngine) GetAvailableVoices() ([]string, error) {
-	resp, err := g.client.ListVoices(g.ctx, &texttospeechpb.ListVoicesRequest{})
+	voices, err := g.GetVoiceInfo()
 	if err != nil {
 		return nil, err
 	}
-	voices := []string{}
-	for _, v := range resp.Voices {
-		voices = append(voices, v.Name)
-	}
-	return voices, nil
+	return voiceNames(voices), nil
 }
 
-// GetCacheStats returns cache statistics for the current engine
-func (g *GoogleClassicTTSEngine) GetCacheStats() (map[string]interface{}, error) {
-	stats := make(map[string]interface{})
-
-	var totalFiles int64
-	var totalSize int64
-
-	// Walk through the entire cache directory tree
-	err := filepath.Walk(g.cacheRootDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // Continue walking despite errors
-		}
-
-		if !info.IsDir() && strings.HasSuffix(strings.ToLower(info.Name()), ".mp3") {
-			totalFiles++
-			totalSize += info.Size()
-		}
-		return nil
-	})
-
-	if err != nil {
-		return stats, err
+// Probe checks the credentials are accepted by listing a few voices, which costs nothing
+func (g *GoogleClassicTTSEngine) Probe(ctx context.Context) error {
+	if _, err := g.client.ListVoices(ctx, &texttospeechpb.ListVoicesRequest{LanguageCode: "en-GB"}); err != nil {
+		return fmt.Errorf("failed to reach Google Text-to-Speech: %w", err)
 	}
-
-	stats["cache_directory"] = g.cacheRootDir
-	stats["cached_files"] = totalFiles
-	stats["total_size_mb"] = float64(totalSize) / (1024 * 1024)
-
-	// Get provider-specific stats if context is available
-	if g.currentProvider != "" {
-		providerDir := filepath.Join(g.cacheRootDir, g.currentProvider, "google_classic")
-		var providerFiles int64
-		var providerSize int64
-
-		filepath.Walk(providerDir, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return nil
-			}
-			if !info.IsDir() && strings.HasSuffix(strings.ToLower(info.Name()), ".mp3") {
-				providerFiles++
-				providerSize += info.Size()
-			}
-			return nil
-		})
-
-		stats["provider"] = g.currentProvider
-		stats["provider_files"] = providerFiles
-		stats["provider_size_mb"] = float64(providerSize) / (1024 * 1024)
-	}
-
-	return stats, nil
-}
-
-// ClearCache removes all cached files
-func (g *GoogleClassicTTSEngine) ClearCache() error {
-	return os.RemoveAll(g.cacheRootDir)
-}
-
-// ClearProviderCache removes cached files for a specific provider
-func (g *GoogleClassicTTSEngine) ClearProviderCache(provider string) error {
-	providerDir := filepath.Join(g.cacheRootDir, provider, "google_classic")
-	return os.RemoveAll(providerDir)
-}
-
-// ClearBookCache removes cached files for a specific book
-func (g *GoogleClassicTTSEngine) ClearBookCache(provider, bookID string) error {
-	bookDir := filepath.Join(g.cacheRootDir, provider, "google_classic", bookID)
-	return os.RemoveAll(bookDir)
+	return nil
 }
 
-// ListCachedBooks returns a list of cached books for each provider
-func (g *GoogleClassicTTSEngine) ListCachedBooks() (map[string][]string, error) {
-	result := make(map[string][]string)
+// GetVoiceInfo describes every voice the API offers, with its language and gender
+func (g *GoogleClassicTTSEngine) GetVoiceInfo() ([]VoiceInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
 
-	// Walk through provider directories
-	providerDirs, err := os.ReadDir(g.cacheRootDir)
+	resp, err := g.client.ListVoices(ctx, &texttospeechpb.ListVoicesRequest{})
 	if err != nil {
-		if os.IsNotExist(err) {
-			return result, nil // Empty cache
-		}
-		return nil, err
-	}
-
-	for _, providerDir := range providerDirs {
-		if !providerDir.IsDir() {
-			continue
-		}
-
-		providerName := providerDir.Name()
-		engineDir := filepath.Join(g.cacheRootDir, providerName, "google_classic")
-
-		bookDirs, err := os.ReadDir(engineDir)
-		if err != nil {
-			continue // Skip if engine directory doesn't exist
-		}
-
-		var books []string
-		for _, bookDir := range bookDirs {
-			if bookDir.IsDir() {
-				books = append(books, bookDir.Name())
-			}
-		}
-
-		if len(books) > 0 {
-			result[providerName] = books
-		}
+		return nil, fmt.Errorf("failed to list voices: %w", err)
 	}
 
-	return result, nil
-}
-
-// helper functions remain the same
-func md5Sum(s string) string {
-	h := md5.New()
-	io.WriteString(h, s)
-	return fmt.Sprintf("%x", h.Sum(nil))
-}
-
-func splitIntoChunks(text string, limit int) []string {
-	var chunks []string
-	runes := []rune(text) // safe for UTF-8
-	for i := 0; i < len(runes); i += limit {
-		end := i + limit
-		if end > len(runes) {
-			end = len(runes)
-		}
-		chunks = append(chunks, string(runes[i:end]))
+	voices := make([]VoiceInfo, 0, len(resp.Voices))
+	for _, v := range resp.Voices {
+		voices = append(voices, googleVoiceInfo(v))
 	}
-	return chunks
+	sortVoices(voices)
+	return voices, nil
 }
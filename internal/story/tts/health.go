@@ -0,0 +1,195 @@
+package tts
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// probeTimeout bounds each engine's health check, so a dead network can't hold up startup
+const probeTimeout = 10 * time.Second
+
+// probeText is synthesized to check that an engine can make audio
+const probeText = "Hello."
+
+// EngineStatus is the outcome of checking one engine
+type EngineStatus struct {
+	Type EngineType
+	Err  error // why the engine can't be used, or nil if it is ready
+}
+
+// AllEngineTypes lists every engine in the order they are usually preferred
+func AllEngineTypes() []EngineType {
+	return []EngineType{
+		EngineTypeGoogleClassic,
+		EngineTypePiper,
+		EngineTypeHTTP,
+		EngineTypeCommand,
+		EngineTypeSAPI,
+		EngineTypeAVFoundation,
+		EngineTypeESpeak,
+		EngineTypeMock,
+	}
+}
+
+// EngineChain is the order engines are tried in: the chosen engine first, then the
+// configured chain (tts.engines), or without one every engine set up on this machine,
+// best first, ending with the silent mock engine
+func EngineChain(engineType string, configured []string) []EngineType {
+	var chain []EngineType
+	seen := map[EngineType]bool{}
+	add := func(t EngineType) {
+		if t != "" && t != EngineTypeAuto && !seen[t] {
+			seen[t] = true
+			chain = append(chain, t)
+		}
+	}
+
+	add(EngineType(engineType))
+	if len(configured) > 0 {
+		for _, t := range configured {
+			add(EngineType(t))
+		}
+		return chain
+	}
+
+	if hasGoogleCredentials() {
+		add(EngineTypeGoogleClassic)
+	}
+	if hasPiper() {
+		add(EngineTypePiper)
+	}
+	if hasHTTP() {
+		add(EngineTypeHTTP)
+	}
+	if hasCommand() {
+		add(EngineTypeCommand)
+	}
+	switch runtime.GOOS {
+	case "windows":
+		add(EngineTypeSAPI)
+	case "darwin":
+		add(EngineTypeAVFoundation)
+	}
+	add(EngineTypeESpeak)
+	add(EngineTypeMock)
+	return chain
+}
+
+// CheckEngine creates an engine and probes it, returning why it can't be used if it fails
+func CheckEngine(ctx context.Context, config Config) (Engine, error) {
+	if config.Type == EngineTypeGoogleClassic.String() && !hasGoogleCredentials() {
+		return nil, fmt.Errorf("GOOGLE_APPLICATION_CREDENTIALS isn't set")
+	}
+
+	engine, err := NewEngine(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if he, ok := engine.(HealthEngine); ok {
+		probeCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+		defer cancel()
+		if err := he.Probe(probeCtx); err != nil {
+			engine.Stop()
+			return nil, err
+		}
+	}
+	return engine, nil
+}
+
+// StartChain starts the first engine in the chain that passes its health check. The
+// engines after it become its fallbacks, created only if it fails part way through a
+// story. It returns the statuses of the engines that failed before it; with
+// none working it falls back to the mock engine.
+func StartChain(ctx context.Context, config Config, chain []EngineType) (Engine, EngineType, []EngineStatus) {
+	chosen := ResolveEngineType(config.Type)
+
+	var failed []EngineStatus
+	for i, engineType := range chain {
+		engineConfig := config
+		engineConfig.Type = engineType.String()
+		if engineType != chosen {
+			// Voices belong to an engine, so the others use their own default
+			engineConfig.Voice = "default"
+		}
+
+		engine, err := CheckEngine(ctx, engineConfig)
+		if err != nil {
+			failed = append(failed, EngineStatus{Type: engineType, Err: err})
+			continue
+		}
+
+		if fe, ok := engine.(FallbackEngine); ok {
+			fe.SetFallbacks(newFallbacks(config, chain[i+1:]))
+		}
+		return engine, engineType, failed
+	}
+
+	config.Type = EngineTypeMock.String()
+	return NewMockTTSEngine(config), EngineTypeMock, failed
+}
+
+// newFallbacks returns a factory for each engine in the chain. They aren't probed: they
+// are only created if the engine in front of them fails, which is check enough.
+func newFallbacks(config Config, chain []EngineType) []EngineFactory {
+	factories := make([]EngineFactory, len(chain))
+	for i, engineType := range chain {
+		engineConfig := config
+		engineConfig.Type, engineConfig.Voice = engineType.String(), "default"
+		factories[i] = func() (Engine, error) {
+			if engineType == EngineTypeGoogleClassic && !hasGoogleCredentials() {
+				return nil, fmt.Errorf("GOOGLE_APPLICATION_CREDENTIALS isn't set")
+			}
+			return NewEngine(engineConfig)
+		}
+	}
+	return factories
+}
+
+// Fallbacks returns the engines to fall back on after engineType, for an engine the
+// user picked themselves rather than one StartChain chose
+func Fallbacks(config Config, engineType EngineType, chain []EngineType) []EngineFactory {
+	for i, t := range chain {
+		if t == engineType {
+			return newFallbacks(config, chain[i+1:])
+		}
+	}
+	return newFallbacks(config, chain)
+}
+
+// Diagnose checks every engine, for explaining why each one can or can't be used.
+// The configured voice is only tried with the engine it belongs to, config.Type.
+func Diagnose(ctx context.Context, config Config) []EngineStatus {
+	chosen := ResolveEngineType(config.Type)
+
+	var statuses []EngineStatus
+	for _, engineType := range AllEngineTypes() {
+		engineConfig := config
+		engineConfig.Type = engineType.String()
+		if engineType != chosen {
+			engineConfig.Voice = "default"
+		}
+
+		engine, err := CheckEngine(ctx, engineConfig)
+		if engine != nil {
+			engine.Stop()
+		}
+		statuses = append(statuses, EngineStatus{Type: engineType, Err: err})
+	}
+	return statuses
+}
+
+// Probe checks the engine can make audio by synthesizing a word
+func (p *player) Probe(ctx context.Context) error {
+	params := p.synth.SynthesisParams()
+	data, _, err := p.synth.Synthesize(ctx, probeText, params)
+	if err != nil {
+		return err
+	}
+	if audioDuration(p.synth.AudioFormat(), data) <= 0 {
+		return fmt.Errorf("%s made audio that can't be played", params.Engine)
+	}
+	return nil
+}
@@ -0,0 +1,410 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// httpSpeedRange is the speed range of the OpenAI speech API
+var httpSpeedRange = Range{Min: 0.25, Max: 4.0}
+
+// httpRetryDelay is the wait before the first retry; it doubles with each one
+var httpRetryDelay = 500 * time.Millisecond
+
+// httpChunkLimit keeps requests well under the 4096 characters OpenAI accepts
+const httpChunkLimit = 1500
+
+// HTTPEngine synthesizes speech with a server that speaks the OpenAI speech API
+// (POST /audio/speech), such as OpenAI itself, Kokoro-FastAPI, openedai-speech or Coqui,
+// and plays it through the shared player
+type HTTPEngine struct {
+	*player
+	spec   httpSpec
+	client *http.Client
+	voice  string
+	speed  float64
+	mutex  sync.RWMutex
+}
+
+// httpSpec is how to reach the speech server, read from tts.http
+type httpSpec struct {
+	BaseURL    string // up to and including /v1
+	Model      string
+	Voice      string // used when no voice has been chosen
+	APIKey     string
+	Format     string // mp3, wav or pcm (16-bit mono PCM at SampleRate)
+	SampleRate int
+	Retries    int
+	Timeout    time.Duration
+}
+
+// loadHTTPSpec reads tts.http and checks it names a server
+func loadHTTPSpec() (httpSpec, error) {
+	spec := httpSpec{
+		BaseURL:    strings.TrimRight(viper.GetString("tts.http.base_url"), "/"),
+		Model:      viper.GetString("tts.http.model"),
+		Voice:      viper.GetString("tts.http.voice"),
+		APIKey:     viper.GetString("tts.http.api_key"),
+		Format:     strings.ToLower(viper.GetString("tts.http.format")),
+		SampleRate: viper.GetInt("tts.http.sample_rate"),
+		Retries:    viper.GetInt("tts.http.retries"),
+		Timeout:    viper.GetDuration("tts.http.timeout"),
+	}
+
+	if spec.BaseURL == "" {
+		return spec, fmt.Errorf("tts.http.base_url isn't set")
+	}
+	if u, err := url.Parse(spec.BaseURL); err != nil || u.Host == "" {
+		return spec, fmt.Errorf("tts.http.base_url '%s' isn't a URL", spec.BaseURL)
+	}
+	switch spec.Format {
+	case "mp3", "wav", "pcm":
+	default:
+		return spec, fmt.Errorf("tts.http.format must be mp3, wav or pcm, not '%s'", spec.Format)
+	}
+	if spec.SampleRate <= 0 {
+		spec.SampleRate = 24000
+	}
+	if spec.Timeout <= 0 {
+		spec.Timeout = time.Minute
+	}
+	return spec, nil
+}
+
+// hasHTTP reports whether a speech server is configured
+func hasHTTP() bool {
+	_, err := loadHTTPSpec()
+	return err == nil
+}
+
+// newHTTPEngine creates an engine for the speech server configured under tts.http
+func newHTTPEngine(config Config) (*HTTPEngine, error) {
+	spec, err := loadHTTPSpec()
+	if err != nil {
+		return nil, err
+	}
+
+	engine := newHTTPEngineWith(spec, config)
+	engine.player = newPlayer(engine, EngineTypeHTTP.String(), OpenAudioCache(), config.Volume)
+	return engine, nil
+}
+
+// newHTTPEngineWith creates the engine's synthesizer half, without a player
+func newHTTPEngineWith(spec httpSpec, config Config) *HTTPEngine {
+	engine := &HTTPEngine{
+		spec:   spec,
+		client: &http.Client{Timeout: spec.Timeout},
+		voice:  config.Voice,
+		speed:  config.Speed,
+	}
+	if engine.speed <= 0 {
+		engine.speed = 1.0
+	}
+	return engine
+}
+
+// SynthesisParams are the voice and speed new audio is made with. The engine is named
+// after the server and model, so audio from different servers is never mixed up.
+func (h *HTTPEngine) SynthesisParams() SynthesisParams {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	voice := h.voice
+	if voice == "" || voice == "default" {
+		voice = h.spec.Voice
+	}
+	return SynthesisParams{Engine: h.engineName(), Voice: voice, Speed: h.speed, ChunkerVersion: chunkerVersion}
+}
+
+// engineName identifies the server and model, e.g. "http/localhost:8880/kokoro"
+func (h *HTTPEngine) engineName() string {
+	host := h.spec.BaseURL
+	if u, err := url.Parse(h.spec.BaseURL); err == nil {
+		host = u.Host
+	}
+	return fmt.Sprintf("%s/%s/%s", EngineTypeHTTP, host, h.spec.Model)
+}
+
+func (h *HTTPEngine) ChunkLimit() int {
+	return httpChunkLimit
+}
+
+// AudioFormat is the format asked for; PCM is handed on as WAV
+func (h *HTTPEngine) AudioFormat() string {
+	if h.spec.Format == "pcm" {
+		return "wav"
+	}
+	return h.spec.Format
+}
+
+// speechRequest is the body of POST /audio/speech
+type speechRequest struct {
+	Model          string  `json:"model,omitempty"`
+	Input          string  `json:"input"`
+	Voice          string  `json:"voice,omitempty"`
+	ResponseFormat string  `json:"response_format"`
+	Speed          float64 `json:"speed,omitempty"`
+}
+
+// httpStatusError is a response from the server that wasn't audio
+type httpStatusError struct {
+	Status     int
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("speech server returned %d: %s", e.Status, e.Message)
+}
+
+// retryable reports whether asking again may work: the server is busy, rate
+// limiting or broken for now, rather than refusing the request
+func (e *httpStatusError) retryable() bool {
+	return e.Status == http.StatusTooManyRequests || e.Status == http.StatusRequestTimeout || e.Status >= 500
+}
+
+// Synthesize posts one chunk to the server, retrying with backoff when it is busy or unreachable
+func (h *HTTPEngine) Synthesize(ctx context.Context, text string, params SynthesisParams) ([]byte, []WordMark, error) {
+	body, err := json.Marshal(speechRequest{
+		Model:          h.spec.Model,
+		Input:          text,
+		Voice:          params.Voice,
+		ResponseFormat: h.spec.Format,
+		Speed:          params.Speed,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode speech request: %w", err)
+	}
+
+	delay := httpRetryDelay
+	for attempt := 0; ; attempt++ {
+		data, err := h.post(ctx, body)
+		if err == nil {
+			if h.spec.Format == "pcm" {
+				data = pcmToWAV(data, h.spec.SampleRate, 1)
+			}
+			return data, nil, nil
+		}
+
+		wait := delay
+		if statusErr, ok := err.(*httpStatusError); ok {
+			if !statusErr.retryable() {
+				return nil, nil, err
+			}
+			if statusErr.RetryAfter > wait {
+				wait = statusErr.RetryAfter
+			}
+		}
+		if attempt >= h.spec.Retries || ctx.Err() != nil {
+			return nil, nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		delay *= 2
+	}
+}
+
+// post makes one request for audio
+func (h *HTTPEngine) post(ctx context.Context, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.spec.BaseURL+"/audio/speech", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create speech request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	h.authorize(req)
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach speech server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read speech response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		statusErr := &httpStatusError{Status: resp.StatusCode, Message: errorMessage(data)}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			statusErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return nil, statusErr
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("speech server returned no audio")
+	}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		return nil, fmt.Errorf("speech server returned JSON instead of audio: %s", errorMessage(data))
+	}
+	return data, nil
+}
+
+// Probe checks the server answers and accepts the API key by asking for its models,
+// so no speech is paid for. A server without GET /models still counts as reachable.
+func (h *HTTPEngine) Probe(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.spec.BaseURL+"/models", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create models request: %w", err)
+	}
+	h.authorize(req)
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach speech server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden || resp.StatusCode >= 500 {
+		data, _ := io.ReadAll(resp.Body)
+		return &httpStatusError{Status: resp.StatusCode, Message: errorMessage(data)}
+	}
+	return nil
+}
+
+func (h *HTTPEngine) authorize(req *http.Request) {
+	if h.spec.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.spec.APIKey)
+	}
+}
+
+// errorMessage pulls the message out of an OpenAI ({"error": {"message"}}) or
+// FastAPI ({"detail"}) error body, or falls back to the start of the body
+func errorMessage(body []byte) string {
+	var parsed struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+		Detail interface{} `json:"detail"`
+	}
+	if json.Unmarshal(body, &parsed) == nil {
+		if parsed.Error.Message != "" {
+			return parsed.Error.Message
+		}
+		if parsed.Detail != nil {
+			return fmt.Sprint(parsed.Detail)
+		}
+	}
+
+	message := strings.TrimSpace(string(body))
+	if len(message) > 200 {
+		message = message[:200] + "..."
+	}
+	return message
+}
+
+func (h *HTTPEngine) SetVoice(voice string) error {
+	h.mutex.Lock()
+	h.voice = voice
+	h.mutex.Unlock()
+	h.resynthesize()
+	return nil
+}
+
+func (h *HTTPEngine) SetSpeed(speed float64) error {
+	if err := httpSpeedRange.check("speed", speed); err != nil {
+		return err
+	}
+
+	h.mutex.Lock()
+	h.speed = speed
+	h.mutex.Unlock()
+	h.resynthesize()
+	return nil
+}
+
+func (h *HTTPEngine) SpeedRange() Range {
+	return httpSpeedRange
+}
+
+func (h *HTTPEngine) GetAvailableVoices() ([]string, error) {
+	voices, err := h.GetVoiceInfo()
+	if err != nil {
+		return nil, err
+	}
+	return voiceNames(voices), nil
+}
+
+// GetVoiceInfo asks the server for its voices at GET /audio/voices, which Kokoro and
+// others offer. OpenAI has no such endpoint, so without it the configured voice is listed.
+func (h *HTTPEngine) GetVoiceInfo() ([]VoiceInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	names, err := h.listVoices(ctx)
+	if err != nil {
+		if h.spec.Voice == "" {
+			return nil, err
+		}
+		names = []string{h.spec.Voice}
+	}
+
+	voices := make([]VoiceInfo, 0, len(names))
+	for _, name := range names {
+		voices = append(voices, VoiceInfo{Name: name, Natural: true})
+	}
+	sortVoices(voices)
+	return voices, nil
+}
+
+// listVoices reads {"voices": [...]} where each voice is a name or an object with a name or id
+func (h *HTTPEngine) listVoices(ctx context.Context) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.spec.BaseURL+"/audio/voices", nil)
+	if err != nil {
+		return nil, err
+	}
+	h.authorize(req)
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach speech server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		return nil, &httpStatusError{Status: resp.StatusCode, Message: errorMessage(data)}
+	}
+
+	var list struct {
+		Voices []json.RawMessage `json:"voices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("failed to parse voice list: %w", err)
+	}
+
+	var names []string
+	for _, raw := range list.Voices {
+		var name string
+		if json.Unmarshal(raw, &name) == nil {
+			names = append(names, name)
+			continue
+		}
+		var voice struct {
+			Name string `json:"name"`
+			ID   string `json:"id"`
+		}
+		if json.Unmarshal(raw, &voice) == nil {
+			if voice.ID != "" {
+				names = append(names, voice.ID)
+			} else if voice.Name != "" {
+				names = append(names, voice.Name)
+			}
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,199 @@
+package tts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestHTTPEngine points an engine at a stand-in speech server
+func newTestHTTPEngine(t *testing.T, handler http.HandlerFunc, format string) *HTTPEngine {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	delay := httpRetryDelay
+	httpRetryDelay = time.Millisecond
+	t.Cleanup(func() { httpRetryDelay = delay })
+
+	return newHTTPEngineWith(httpSpec{
+		BaseURL:    server.URL + "/v1",
+		Model:      "kokoro",
+		Voice:      "af_bella",
+		APIKey:     "secret",
+		Format:     format,
+		SampleRate: 24000,
+		Retries:    2,
+		Timeout:    5 * time.Second,
+	}, Config{Speed: 1.5})
+}
+
+func TestHTTPEngineSynthesize(t *testing.T) {
+	engine := newTestHTTPEngine(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/audio/speech" {
+			t.Errorf("got %s %s, want POST /v1/audio/speech", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+
+		var req speechRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("bad request body: %v", err)
+			http.Error(w, "bad request body", http.StatusBadRequest)
+			return
+		}
+		want := speechRequest{Model: "kokoro", Input: "Once upon a time", Voice: "af_bella", ResponseFormat: "pcm", Speed: 1.5}
+		if req != want {
+			t.Errorf("request = %+v, want %+v", req, want)
+		}
+
+		w.Header().Set("Content-Type", "audio/pcm")
+		w.Write(make([]byte, 4800)) // 0.1s of 24kHz 16-bit mono
+	}, "pcm")
+
+	data, marks, err := engine.Synthesize(context.Background(), "Once upon a time", engine.SynthesisParams())
+	if err != nil {
+		t.Fatalf("Synthesize: %v", err)
+	}
+	if marks != nil {
+		t.Errorf("marks = %v, want none", marks)
+	}
+	if !strings.HasPrefix(string(data), "RIFF") || len(data) != 44+4800 {
+		t.Fatalf("got %d bytes, want PCM wrapped as WAV", len(data))
+	}
+	if got := audioDuration(engine.AudioFormat(), data); got != 100*time.Millisecond {
+		t.Errorf("duration = %v, want 100ms", got)
+	}
+}
+
+func TestHTTPEngineRetriesWhenBusy(t *testing.T) {
+	var requests int32
+	engine := newTestHTTPEngine(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Write([]byte("mp3 data"))
+	}, "mp3")
+
+	data, _, err := engine.Synthesize(context.Background(), "Hello", engine.SynthesisParams())
+	if err != nil {
+		t.Fatalf("Synthesize: %v", err)
+	}
+	if string(data) != "mp3 data" {
+		t.Errorf("data = %q", data)
+	}
+	if requests != 3 {
+		t.Errorf("made %d requests, want 3", requests)
+	}
+}
+
+func TestHTTPEngineGivesUpAfterRetries(t *testing.T) {
+	var requests int32
+	engine := newTestHTTPEngine(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}, "mp3")
+
+	_, _, err := engine.Synthesize(context.Background(), "Hello", engine.SynthesisParams())
+	if err == nil || !strings.Contains(err.Error(), "502") {
+		t.Fatalf("err = %v, want a 502 error", err)
+	}
+	if requests != 3 {
+		t.Errorf("made %d requests, want 3", requests)
+	}
+}
+
+func TestHTTPEngineDoesNotRetryRejectedRequests(t *testing.T) {
+	var requests int32
+	engine := newTestHTTPEngine(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": {"message": "Incorrect API key provided"}}`))
+	}, "mp3")
+
+	_, _, err := engine.Synthesize(context.Background(), "Hello", engine.SynthesisParams())
+	if err == nil || !strings.Contains(err.Error(), "Incorrect API key provided") {
+		t.Fatalf("err = %v, want the server's message", err)
+	}
+	if requests != 1 {
+		t.Errorf("made %d requests, want 1", requests)
+	}
+}
+
+func TestHTTPEngineStopsRetryingWhenCancelled(t *testing.T) {
+	engine := newTestHTTPEngine(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}, "mp3")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, _, err := engine.Synthesize(ctx, "Hello", engine.SynthesisParams()); err == nil {
+		t.Fatal("Synthesize succeeded, want an error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("took %v, want it to give up when the context ends", elapsed)
+	}
+}
+
+func TestHTTPEngineVoices(t *testing.T) {
+	engine := newTestHTTPEngine(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/audio/voices" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"voices": ["am_adam", {"id": "af_bella", "name": "Bella"}]}`))
+	}, "mp3")
+
+	voices, err := engine.GetAvailableVoices()
+	if err != nil {
+		t.Fatalf("GetAvailableVoices: %v", err)
+	}
+	if strings.Join(voices, ",") != "af_bella,am_adam" {
+		t.Errorf("voices = %v", voices)
+	}
+}
+
+func TestHTTPEngineVoicesWithoutListEndpoint(t *testing.T) {
+	engine := newTestHTTPEngine(t, http.NotFound, "mp3")
+
+	voices, err := engine.GetAvailableVoices()
+	if err != nil {
+		t.Fatalf("GetAvailableVoices: %v", err)
+	}
+	if len(voices) != 1 || voices[0] != "af_bella" {
+		t.Errorf("voices = %v, want just the configured voice", voices)
+	}
+}
+
+func TestHTTPEngineProbe(t *testing.T) {
+	status := http.StatusNotFound
+	engine := newTestHTTPEngine(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/models" {
+			t.Errorf("got %s %s, want GET /v1/models", r.Method, r.URL.Path)
+		}
+		http.Error(w, `{"error": {"message": "Incorrect API key"}}`, status)
+	}, "mp3")
+
+	// A server without a models endpoint still answers
+	if err := engine.Probe(context.Background()); err != nil {
+		t.Errorf("Probe with no models endpoint: %v", err)
+	}
+
+	status = http.StatusUnauthorized
+	err := engine.Probe(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "Incorrect API key") {
+		t.Errorf("Probe with a bad key = %v, want the server's message", err)
+	}
+}
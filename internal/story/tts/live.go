@@ -0,0 +1,120 @@
+package tts
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/faiface/beep"
+)
+
+// liveAudio is the raw 16-bit mono PCM of a chunk that is still being synthesized,
+// so the chunk can start playing before it is finished
+type liveAudio struct {
+	mu         sync.Mutex
+	pcm        []byte
+	sampleRate int
+	done       bool
+}
+
+func newLiveAudio(sampleRate int) *liveAudio {
+	return &liveAudio{sampleRate: sampleRate}
+}
+
+func (l *liveAudio) write(pcm []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pcm = append(l.pcm, pcm...)
+}
+
+// finish marks the audio as complete, whether synthesis succeeded or not
+func (l *liveAudio) finish() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.done = true
+}
+
+func (l *liveAudio) complete() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.done
+}
+
+// bytes returns the PCM made so far
+func (l *liveAudio) bytes() []byte {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]byte(nil), l.pcm...)
+}
+
+func (l *liveAudio) format() beep.Format {
+	return beep.Format{SampleRate: beep.SampleRate(l.sampleRate), NumChannels: 1, Precision: 2}
+}
+
+// samples is how many whole samples have arrived. The caller holds l.mu.
+func (l *liveAudio) samples() int {
+	return len(l.pcm) / 2
+}
+
+// liveStreamer plays liveAudio as it arrives. When playback catches up with synthesis
+// it plays silence until there is more, and it ends once the audio is complete.
+type liveStreamer struct {
+	live *liveAudio
+	pos  int
+}
+
+func (s *liveStreamer) Stream(samples [][2]float64) (int, bool) {
+	s.live.mu.Lock()
+	defer s.live.mu.Unlock()
+
+	available := s.live.samples() - s.pos
+	if available <= 0 {
+		if s.live.done {
+			return 0, false
+		}
+		for i := range samples {
+			samples[i] = [2]float64{}
+		}
+		return len(samples), true
+	}
+
+	n := min(len(samples), available)
+	for i := 0; i < n; i++ {
+		at := 2 * (s.pos + i)
+		v := float64(int16(binary.LittleEndian.Uint16(s.live.pcm[at:]))) / (1 << 15)
+		samples[i] = [2]float64{v, v}
+	}
+	s.pos += n
+	return n, true
+}
+
+func (s *liveStreamer) Err() error {
+	return nil
+}
+
+// Len is the length of the audio that has arrived so far
+func (s *liveStreamer) Len() int {
+	s.live.mu.Lock()
+	defer s.live.mu.Unlock()
+	return s.live.samples()
+}
+
+func (s *liveStreamer) Position() int {
+	s.live.mu.Lock()
+	defer s.live.mu.Unlock()
+	return s.pos
+}
+
+func (s *liveStreamer) Seek(p int) error {
+	if p < 0 || p > s.Len() {
+		return fmt.Errorf("seek position %v out of range [%v, %v]", p, 0, s.Len())
+	}
+	s.live.mu.Lock()
+	defer s.live.mu.Unlock()
+	s.pos = p
+	return nil
+}
+
+func (s *liveStreamer) Close() error {
+	return nil
+}
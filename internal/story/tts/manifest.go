@@ -0,0 +1,242 @@
+package tts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// manifestFile sits in each book's cache directory and describes every chunk of audio in it
+	manifestFile    = "manifest.json"
+	manifestVersion = 1
+
+	// manifestLockFile keeps other storynest processes from saving a book's manifest at the same time
+	manifestLockFile = "manifest.lock"
+	// manifestLockWait is how long to wait for another writer to finish with a manifest
+	manifestLockWait = 10 * time.Second
+	// staleManifestLock is how old a lock file has to be before it is taken to be left
+	// behind by a process that died while holding it
+	staleManifestLock = 30 * time.Second
+)
+
+// manifestLocks keeps writers in this process from saving the same manifest at once, keyed by directory
+var manifestLocks sync.Map
+
+// SynthesisParams are the settings a chunk of audio was synthesized with.
+// Cached audio is only reused when all of them match.
+type SynthesisParams struct {
+	Engine         string  `json:"engine"`
+	Voice          string  `json:"voice"`
+	Speed          float64 `json:"speed"`
+	Pitch          float64 `json:"pitch"`
+	Volume         float64 `json:"volume"`
+	ChunkerVersion string  `json:"chunker_version"`
+}
+
+// chunkKey names the audio for one chunk of text made with these settings
+func (p SynthesisParams) chunkKey(text string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%g\x00%g\x00%g\x00%s\x00%s",
+		p.Engine, p.Voice, p.Speed, p.Pitch, p.Volume, p.ChunkerVersion, text)
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// ManifestChunk describes one cached audio file
+type ManifestChunk struct {
+	SynthesisParams
+	TextHash  string        `json:"text_hash"` // SHA-256 of the chunk text
+	Duration  time.Duration `json:"duration"`
+	Size      int64         `json:"size"`
+	Checksum  string        `json:"sha256"` // SHA-256 of the audio file
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// Manifest lists the audio cached for one book, keyed by file name
+type Manifest struct {
+	Version int                      `json:"version"`
+	Chunks  map[string]ManifestChunk `json:"chunks"`
+
+	dir     string
+	changed map[string]bool // files added or removed since the manifest was read
+}
+
+// loadManifest reads the manifest in dir. If there is none, or it can't be read, an
+// empty manifest is returned so the audio is made again; the error says why.
+func loadManifest(dir string) (*Manifest, error) {
+	m := &Manifest{Version: manifestVersion, Chunks: make(map[string]ManifestChunk), dir: dir}
+
+	data, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+		return m, fmt.Errorf("failed to read cache manifest: %w", err)
+	}
+
+	var saved Manifest
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return m, fmt.Errorf("failed to parse cache manifest %s: %w", filepath.Join(dir, manifestFile), err)
+	}
+	if saved.Version != manifestVersion || saved.Chunks == nil {
+		return m, nil
+	}
+
+	saved.dir = dir
+	return &saved, nil
+}
+
+// save writes the manifest atomically, so a crash leaves either the old or the new one.
+// Other writers may have saved the book's manifest since this one was read, e.g. a
+// prefetch running alongside playback, so only the chunks changed here are merged
+// into what is on disk. The manifest then holds everything the others added too.
+func (m *Manifest) save() error {
+	unlock, err := lockManifest(m.dir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	saved, err := loadManifest(m.dir)
+	if err != nil {
+		// Unreadable, so it has nothing worth keeping
+		saved = &Manifest{Version: manifestVersion, Chunks: make(map[string]ManifestChunk), dir: m.dir}
+	}
+	for file := range m.changed {
+		if chunk, ok := m.Chunks[file]; ok {
+			saved.Chunks[file] = chunk
+		} else {
+			delete(saved.Chunks, file)
+		}
+	}
+
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cache manifest: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(m.dir, manifestFile), data); err != nil {
+		return fmt.Errorf("failed to write cache manifest: %w", err)
+	}
+
+	m.Chunks = saved.Chunks
+	m.changed = nil
+	return nil
+}
+
+// lockManifest waits until no other writer, in this process or another, is saving the
+// manifest in dir and keeps them out until unlock is called
+func lockManifest(dir string) (unlock func(), err error) {
+	mu, _ := manifestLocks.LoadOrStore(dir, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+
+	path := filepath.Join(dir, manifestLockFile)
+	deadline := time.Now().Add(manifestLockWait)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() {
+				os.Remove(path)
+				mu.(*sync.Mutex).Unlock()
+			}, nil
+		}
+		if !os.IsExist(err) {
+			mu.(*sync.Mutex).Unlock()
+			return nil, fmt.Errorf("failed to lock cache manifest: %w", err)
+		}
+
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleManifestLock {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			mu.(*sync.Mutex).Unlock()
+			return nil, fmt.Errorf("timed out waiting for another storynest to finish with %s", filepath.Join(dir, manifestFile))
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// add records audio that has just been written to file
+func (m *Manifest) add(file string, params SynthesisParams, text string, audio []byte, duration time.Duration) {
+	m.markChanged(file)
+	m.Chunks[file] = ManifestChunk{
+		SynthesisParams: params,
+		TextHash:        hashHex([]byte(text)),
+		Duration:        duration,
+		Size:            int64(len(audio)),
+		Checksum:        hashHex(audio),
+		CreatedAt:       time.Now(),
+	}
+}
+
+// remove forgets a file, e.g. one that failed its check and was deleted
+func (m *Manifest) remove(file string) {
+	delete(m.Chunks, file)
+	m.markChanged(file)
+}
+
+func (m *Manifest) markChanged(file string) {
+	if m.changed == nil {
+		m.changed = make(map[string]bool)
+	}
+	m.changed[file] = true
+}
+
+// verify reports whether file holds intact audio of text made with params
+func (m *Manifest) verify(file string, params SynthesisParams, text string) bool {
+	chunk, ok := m.Chunks[file]
+	if !ok || chunk.SynthesisParams != params || chunk.TextHash != hashHex([]byte(text)) {
+		return false
+	}
+	return m.check(file) == nil
+}
+
+// check makes sure a file listed in the manifest is still the file that was written.
+// The error describes what is wrong with it.
+func (m *Manifest) check(file string) error {
+	chunk, ok := m.Chunks[file]
+	if !ok {
+		return fmt.Errorf("isn't in the manifest")
+	}
+
+	data, err := os.ReadFile(filepath.Join(m.dir, file))
+	if os.IsNotExist(err) {
+		return fmt.Errorf("is missing")
+	}
+	if err != nil {
+		return fmt.Errorf("can't be read: %w", err)
+	}
+	if int64(len(data)) != chunk.Size {
+		return fmt.Errorf("is %d bytes, expected %d", len(data), chunk.Size)
+	}
+	if hashHex(data) != chunk.Checksum {
+		return fmt.Errorf("doesn't match its checksum")
+	}
+	return nil
+}
+
+// voices lists the voices the audio in the manifest was made with
+func (m *Manifest) voices() []string {
+	var voices []string
+	seen := make(map[string]bool)
+	for _, chunk := range m.Chunks {
+		if !seen[chunk.Voice] {
+			seen[chunk.Voice] = true
+			voices = append(voices, chunk.Voice)
+		}
+	}
+	sort.Strings(voices)
+	return voices
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,37 @@
+package tts
+
+import (
+	"context"
+	"testing"
+)
+
+func TestManifestKeepsChunksSavedByOtherWriters(t *testing.T) {
+	cache := NewAudioCache(t.TempDir(), 0, true)
+	p := (&fakeSynth{}).player(cache)
+
+	// e.g. playback and a prefetch of the same book, both opened before either saves
+	var books []*bookAudio
+	for range 2 {
+		book, err := p.bookAudio("test", "book", p.synth.SynthesisParams())
+		if err != nil {
+			t.Fatalf("bookAudio: %v", err)
+		}
+		books = append(books, book)
+	}
+	texts := []string{"The first part.", "The second part."}
+	for i, book := range books {
+		if _, _, err := book.chunk(context.Background(), texts[i], nil); err != nil {
+			t.Fatalf("chunk: %v", err)
+		}
+	}
+
+	manifest, err := loadManifest(books[0].dir)
+	if err != nil {
+		t.Fatalf("loadManifest: %v", err)
+	}
+	for _, text := range texts {
+		if !manifest.verify(books[0].file(text), books[0].params, text) {
+			t.Errorf("%q is missing from the manifest", text)
+		}
+	}
+}
@@ -1,12 +1,19 @@
 package tts
 
 import (
+	"context"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fatih/color"
 )
 
+var (
+	mockSpeedRange  = Range{Min: 0.1, Max: 3.0}
+	mockVolumeRange = Range{Min: 0, Max: 2.0}
+)
+
 // MockTTSEngine - placeholder implementation
 type MockTTSEngine struct {
 	playing bool
@@ -14,17 +21,41 @@ type MockTTSEngine struct {
 	speed   float64
 	volume  float64
 	voice   string
+	events  eventHub
+	mu      sync.Mutex
 }
 
-func (m *MockTTSEngine) SetBookContext(provider, bookID string) {
-	//TODO implement me
-	panic("implement me")
+// SetBookContext does nothing: the mock engine makes no audio to cache
+func (m *MockTTSEngine) SetBookContext(provider, bookID string) {}
+
+// Capabilities are what the simulation can do: pause, and change speed and volume
+func (m *MockTTSEngine) Capabilities() Capabilities {
+	return Capabilities{
+		Pause:  true,
+		Speed:  mockSpeedRange,
+		Volume: mockVolumeRange,
+	}
 }
 
 func (m *MockTTSEngine) GetAvailableVoices() ([]string, error) {
 	return []string{"mock-voice"}, nil
 }
 
+func (m *MockTTSEngine) GetVoiceInfo() ([]VoiceInfo, error) {
+	return []VoiceInfo{{
+		Name:         "mock-voice",
+		LanguageCode: "en-US",
+		Gender:       GenderNeutral,
+		Description:  "Prints the text instead of speaking it",
+	}}, nil
+}
+
+func (m *MockTTSEngine) IsPaused() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.paused
+}
+
 func NewMockTTSEngine(c Config) *MockTTSEngine {
 	return &MockTTSEngine{
 		speed:  c.Speed,
@@ -34,8 +65,15 @@ func NewMockTTSEngine(c Config) *MockTTSEngine {
 }
 
 func (m *MockTTSEngine) Speak(text string) error {
+	return m.SpeakContext(context.Background(), text)
+}
+
+// SpeakContext simulates speaking until the reading time is up or ctx is cancelled
+func (m *MockTTSEngine) SpeakContext(ctx context.Context, text string) error {
+	m.mu.Lock()
 	m.playing = true
 	m.paused = false
+	m.mu.Unlock()
 
 	// Simulate reading time based on text length
 	words := len(strings.Fields(text))
@@ -47,46 +85,121 @@ func (m *MockTTSEngine) Speak(text string) error {
 	// - github.com/hajimehoshi/oto for audio output
 	// - A TTS library like eSpeak, Festival, or cloud TTS APIs
 
-	time.Sleep(2 * time.Second) // Simulate some reading time
-	m.playing = false
+	run := m.events.start()
+	chunks := []textChunk{{Text: text}}
+	m.events.chunk(run, 0, chunks)
+
+	go func() {
+		// Simulate some reading time, holding still while paused
+		for remaining := 2 * time.Second; remaining > 0; {
+			select {
+			case <-ctx.Done():
+				m.mu.Lock()
+				if m.events.current() == run {
+					m.playing = false
+					m.paused = false
+				}
+				m.mu.Unlock()
+				m.events.finish(run, contextError(ctx.Err()))
+				return
+			case <-time.After(100 * time.Millisecond):
+			}
+			m.mu.Lock()
+			if m.events.current() != run || !m.playing {
+				m.mu.Unlock()
+				return
+			}
+			if !m.paused {
+				remaining -= 100 * time.Millisecond
+			}
+			m.mu.Unlock()
+		}
+
+		m.mu.Lock()
+		m.playing = false
+		m.paused = false
+		m.mu.Unlock()
+		m.events.finish(run, nil)
+	}()
+
 	return nil
 }
 
+// Subscribe returns a channel of playback events
+func (m *MockTTSEngine) Subscribe() (<-chan Event, func()) {
+	return m.events.Subscribe()
+}
+
 func (m *MockTTSEngine) SetVoice(voice string) error {
 	m.voice = voice
 	return nil
 }
 
 func (m *MockTTSEngine) SetSpeed(speed float64) error {
+	if err := mockSpeedRange.check("speed", speed); err != nil {
+		return err
+	}
 	m.speed = speed
 	return nil
 }
 
 func (m *MockTTSEngine) SetVolume(volume float64) error {
+	if err := mockVolumeRange.check("volume", volume); err != nil {
+		return err
+	}
 	m.volume = volume
 	return nil
 }
 
+func (m *MockTTSEngine) SpeedRange() Range {
+	return mockSpeedRange
+}
+
+func (m *MockTTSEngine) VolumeRange() Range {
+	return mockVolumeRange
+}
+
 func (m *MockTTSEngine) Stop() error {
+	m.mu.Lock()
 	m.playing = false
 	m.paused = false
+	m.mu.Unlock()
+	m.events.finishCurrent()
 	return nil
 }
 
 func (m *MockTTSEngine) Pause() error {
-	if m.playing {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.playing && !m.paused {
 		m.paused = true
+		m.events.emitCurrent(Event{Type: EventPaused})
 	}
 	return nil
 }
 
 func (m *MockTTSEngine) Resume() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.paused {
 		m.paused = false
+		m.events.emitCurrent(Event{Type: EventResumed})
 	}
 	return nil
 }
 
 func (m *MockTTSEngine) IsPlaying() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	return m.playing && !m.paused
 }
+
+func (m *MockTTSEngine) FadeVolume(level float64, over time.Duration) {
+	color.Yellow("🔉 Fading volume to %.0f%% over %v (simulated)", level*100, over)
+}
+
+func (m *MockTTSEngine) StopAtBoundary() {
+	m.Stop()
+}
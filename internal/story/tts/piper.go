@@ -0,0 +1,320 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// piperChunkLimit keeps each Piper run to a few sentences. Its audio plays as it is
+// made, so this only bounds the PCM a run holds in memory.
+const piperChunkLimit = 500
+
+// piperSpeedRange is applied through Piper's length scale, where 2 reads twice as fast
+var piperSpeedRange = Range{Min: 0.5, Max: 2.0}
+
+// PiperEngine synthesizes speech locally with the Piper neural TTS binary and an
+// ONNX voice model, and plays it through the shared player
+type PiperEngine struct {
+	*player
+	path      string // the piper binary
+	modelsDir string
+	voice     string
+	speed     float64
+	mutex     sync.RWMutex
+}
+
+// piperModel is an installed voice: model.onnx and the model.onnx.json Piper reads beside it
+type piperModel struct {
+	Name       string
+	Path       string
+	SampleRate int
+	Language   string
+	Dataset    string
+	Quality    string
+}
+
+// newPiperEngine creates a Piper engine using the binary and voice models from the config
+func newPiperEngine(config Config) (*PiperEngine, error) {
+	path, err := findPiperExecutable()
+	if err != nil {
+		return nil, err
+	}
+
+	engine := &PiperEngine{
+		path:      path,
+		modelsDir: viper.GetString("tts.piper.models_dir"),
+		speed:     config.Speed,
+	}
+	if engine.speed <= 0 {
+		engine.speed = 1.0
+	}
+	engine.player = newPlayer(engine, EngineTypePiper.String(), OpenAudioCache(), config.Volume)
+
+	models, err := engine.models()
+	if err != nil {
+		return nil, err
+	}
+	if len(models) == 0 {
+		return nil, fmt.Errorf("no Piper voice models (.onnx with .onnx.json) found in %s", engine.modelsDir)
+	}
+
+	// A model that has been removed falls back to the first one installed
+	engine.voice = models[0].Name
+	for _, m := range models {
+		if m.Name == config.Voice {
+			engine.voice = m.Name
+		}
+	}
+
+	return engine, nil
+}
+
+// findPiperExecutable uses tts.piper.path if it is set, otherwise looks for piper on the PATH
+func findPiperExecutable() (string, error) {
+	if path := viper.GetString("tts.piper.path"); path != "" {
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("piper not found at %s: %w", path, err)
+		}
+		return path, nil
+	}
+
+	for _, candidate := range []string{"piper", "piper-tts"} {
+		if path, err := exec.LookPath(candidate); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("piper executable not found in PATH (or set tts.piper.path)")
+}
+
+// hasPiper reports whether Piper and at least one voice model are installed
+func hasPiper() bool {
+	if _, err := findPiperExecutable(); err != nil {
+		return false
+	}
+	models, err := loadPiperModels(viper.GetString("tts.piper.models_dir"))
+	return err == nil && len(models) > 0
+}
+
+// models lists the voice models installed in the models directory
+func (p *PiperEngine) models() ([]piperModel, error) {
+	return loadPiperModels(p.modelsDir)
+}
+
+// model reads the installed model named voice. Names are file names in the models
+// directory, so anything that could lead out of it is refused.
+func (p *PiperEngine) model(voice string) (piperModel, error) {
+	if voice == "" || voice == "." || voice == ".." || filepath.Base(voice) != voice || strings.ContainsAny(voice, `/\`) {
+		return piperModel{}, fmt.Errorf("'%s' isn't a Piper voice name", voice)
+	}
+	return readPiperModel(filepath.Join(p.modelsDir, voice+".onnx"))
+}
+
+// loadPiperModels reads every model in dir that has its JSON config beside it, sorted by name
+func loadPiperModels(dir string) ([]piperModel, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.onnx"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Piper models: %w", err)
+	}
+
+	var models []piperModel
+	for _, path := range paths {
+		model, err := readPiperModel(path)
+		if err != nil {
+			continue
+		}
+		models = append(models, model)
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].Name < models[j].Name })
+	return models, nil
+}
+
+// readPiperModel reads the sample rate and language of a model from its .onnx.json
+func readPiperModel(path string) (piperModel, error) {
+	data, err := os.ReadFile(path + ".json")
+	if err != nil {
+		return piperModel{}, err
+	}
+
+	var config struct {
+		Audio struct {
+			SampleRate int    `json:"sample_rate"`
+			Quality    string `json:"quality"`
+		} `json:"audio"`
+		Language struct {
+			Code string `json:"code"`
+		} `json:"language"`
+		Dataset string `json:"dataset"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return piperModel{}, fmt.Errorf("failed to parse %s.json: %w", path, err)
+	}
+	if config.Audio.SampleRate == 0 {
+		config.Audio.SampleRate = 22050
+	}
+
+	return piperModel{
+		Name:       strings.TrimSuffix(filepath.Base(path), ".onnx"),
+		Path:       path,
+		SampleRate: config.Audio.SampleRate,
+		Language:   config.Language.Code,
+		Dataset:    config.Dataset,
+		Quality:    config.Audio.Quality,
+	}, nil
+}
+
+// SynthesisParams are the model and speed new audio is made with
+func (p *PiperEngine) SynthesisParams() SynthesisParams {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return SynthesisParams{Engine: EngineTypePiper.String(), Voice: p.voice, Speed: p.speed, ChunkerVersion: chunkerVersion}
+}
+
+func (p *PiperEngine) ChunkLimit() int {
+	return piperChunkLimit
+}
+
+func (p *PiperEngine) AudioFormat() string {
+	return "wav"
+}
+
+// Synthesize pipes a chunk through Piper and wraps all of its PCM as WAV
+func (p *PiperEngine) Synthesize(ctx context.Context, text string, params SynthesisParams) ([]byte, []WordMark, error) {
+	out, sampleRate, err := p.SynthesizeStream(ctx, text, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	pcm, readErr := io.ReadAll(out)
+	if err := out.Close(); err != nil {
+		return nil, nil, err
+	}
+	if readErr != nil {
+		return nil, nil, fmt.Errorf("failed to read Piper's audio: %w", readErr)
+	}
+	if len(pcm) == 0 {
+		return nil, nil, fmt.Errorf("piper produced no audio")
+	}
+	return pcmToWAV(pcm, sampleRate, 1), nil, nil
+}
+
+// SynthesizeStream starts Piper on a chunk and returns its stdout, where it writes raw
+// 16-bit mono PCM at the model's sample rate a sentence at a time
+func (p *PiperEngine) SynthesizeStream(ctx context.Context, text string, params SynthesisParams) (io.ReadCloser, int, error) {
+	model, err := p.model(params.Voice)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load Piper voice %s: %w", params.Voice, err)
+	}
+
+	// Length scale stretches the speech, so it is the inverse of speed
+	cmd := exec.CommandContext(ctx, p.path,
+		"--model", model.Path,
+		"--output_raw",
+		"--length_scale", strconv.FormatFloat(1/params.Speed, 'f', 3, 64))
+	cmd.Stdin = strings.NewReader(text)
+	out := &piperOutput{cmd: cmd}
+	cmd.Stderr = &out.stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to start piper: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, 0, fmt.Errorf("failed to start piper: %w", err)
+	}
+	out.ReadCloser = stdout
+	return out, model.SampleRate, nil
+}
+
+// piperOutput is a running Piper's stdout. Closing it waits for Piper to exit.
+type piperOutput struct {
+	io.ReadCloser
+	cmd    *exec.Cmd
+	stderr bytes.Buffer
+}
+
+func (o *piperOutput) Close() error {
+	// Closing stdout first stops a Piper that is still writing
+	o.ReadCloser.Close()
+	if err := o.cmd.Wait(); err != nil {
+		if msg := strings.TrimSpace(o.stderr.String()); msg != "" {
+			return fmt.Errorf("piper failed: %w: %s", err, msg)
+		}
+		return fmt.Errorf("piper failed: %w", err)
+	}
+	return nil
+}
+
+// SetVoice picks an installed model by name, e.g. "en_GB-alba-medium"
+func (p *PiperEngine) SetVoice(voice string) error {
+	if _, err := p.model(voice); err != nil {
+		return fmt.Errorf("voice '%s' not available", voice)
+	}
+
+	p.mutex.Lock()
+	p.voice = voice
+	p.mutex.Unlock()
+	p.resynthesize()
+	return nil
+}
+
+func (p *PiperEngine) SetSpeed(speed float64) error {
+	if err := piperSpeedRange.check("speed", speed); err != nil {
+		return err
+	}
+
+	p.mutex.Lock()
+	p.speed = speed
+	p.mutex.Unlock()
+	p.resynthesize()
+	return nil
+}
+
+func (p *PiperEngine) SpeedRange() Range {
+	return piperSpeedRange
+}
+
+func (p *PiperEngine) GetAvailableVoices() ([]string, error) {
+	voices, err := p.GetVoiceInfo()
+	if err != nil {
+		return nil, err
+	}
+	return voiceNames(voices), nil
+}
+
+// GetVoiceInfo describes the installed models. Piper doesn't record a speaker's gender.
+func (p *PiperEngine) GetVoiceInfo() ([]VoiceInfo, error) {
+	models, err := p.models()
+	if err != nil {
+		return nil, err
+	}
+
+	voices := make([]VoiceInfo, 0, len(models))
+	for _, m := range models {
+		description := "Piper voice"
+		if m.Dataset != "" {
+			description += " " + m.Dataset
+		}
+		if m.Quality != "" {
+			description += ", " + m.Quality + " quality"
+		}
+		voices = append(voices, VoiceInfo{
+			Name:         m.Name,
+			LanguageCode: languageTag(m.Language),
+			Natural:      true,
+			Description:  description,
+		})
+	}
+	sortVoices(voices)
+	return voices, nil
+}
@@ -0,0 +1,871 @@
+package tts
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+	"github.com/faiface/beep/speaker"
+)
+
+// playerVolumeRange is the playback volume range, where 1 plays the audio as synthesized
+var playerVolumeRange = Range{Min: 0, Max: 2.0}
+
+// player plays the audio from a Synthesizer through the shared speaker. Engines embed
+// it, so pausing, seeking, volume, fades, caching and export work the same for all of them.
+type player struct {
+	synth Synthesizer
+	name  string // directory the engine's audio is cached under
+	cache *AudioCache
+
+	mu        sync.Mutex // guards the settings below; never taken under the speaker lock
+	provider  string
+	bookID    string
+	volume    float64
+	fallbacks []*fallback        // carry on synthesizing, in order, when synth fails
+	gen       int                // counts Speaks and Stops, so a Stop while the first chunk is made wins
+	starting  context.CancelFunc // cancels the first chunk of a Speak that hasn't started playing yet
+
+	// Playback state, guarded by the speaker lock
+	queue *chunkQueue
+	ctrl  *beep.Ctrl
+	vol   *effects.Volume
+	fade  float64
+	fader volumeFader
+
+	events eventHub
+}
+
+func newPlayer(synth Synthesizer, name string, cache *AudioCache, volume float64) *player {
+	if volume <= 0 {
+		volume = 1.0
+	}
+	return &player{
+		synth:  synth,
+		name:   name,
+		cache:  cache,
+		volume: volume,
+		fade:   1.0,
+	}
+}
+
+// SetBookContext sets the provider and book the next text belongs to, so its audio is
+// cached with the book. Text spoken without a book is synthesized but not cached.
+func (p *player) SetBookContext(provider, bookID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.provider = provider
+	p.bookID = bookID
+}
+
+// SetFallbacks sets the engines that carry on synthesizing, in order, if this one fails
+// part way through. Each is created the first time it's needed. Their audio plays
+// through this player, so only engines built on the player can stand in.
+func (p *player) SetFallbacks(factories []EngineFactory) {
+	fallbacks := make([]*fallback, len(factories))
+	for i, create := range factories {
+		fallbacks[i] = &fallback{create: create}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fallbacks = fallbacks
+}
+
+// fallback is an engine that stands in when the one before it fails, created on first use
+type fallback struct {
+	create EngineFactory
+	once   sync.Once
+	player *player
+	err    error
+}
+
+// pipeline creates the engine the first time it's asked for and returns its player
+func (f *fallback) pipeline() (*player, error) {
+	f.once.Do(func() {
+		engine, err := f.create()
+		if err != nil {
+			f.err = err
+			return
+		}
+		pe, ok := engine.(playerEngine)
+		if !ok {
+			engine.Stop()
+			f.err = fmt.Errorf("%T can't stand in for another engine", engine)
+			return
+		}
+		f.player = pe.pipeline()
+	})
+	return f.player, f.err
+}
+
+// chunkText splits text into the chunks the synthesizer makes audio for in one go
+func (p *player) chunkText(text string) []textChunk {
+	size := utf8.RuneCountInString
+	if m, ok := p.synth.(chunkMeasurer); ok {
+		size = m.ChunkSize
+	}
+	return chunkText(text, p.synth.ChunkLimit(), size)
+}
+
+// playerEngine is an engine that plays through an embedded player
+type playerEngine interface {
+	pipeline() *player
+}
+
+func (p *player) pipeline() *player {
+	return p
+}
+
+func (p *player) Speak(text string) error {
+	return p.SpeakContext(context.Background(), text)
+}
+
+// SpeakContext starts playing the text once its first chunk has audio, or as soon as
+// the first of it arrives from a synthesizer that streams; the rest is synthesized in
+// the background ahead of playback. Cancelling ctx stops synthesis and playback.
+func (p *player) SpeakContext(ctx context.Context, text string) error {
+	p.mu.Lock()
+	p.stop()
+
+	chunks := p.chunkText(text)
+	if len(chunks) == 0 {
+		p.mu.Unlock()
+		return nil
+	}
+
+	if p.provider != "" && p.bookID != "" {
+		if err := p.cache.Played(p.provider, p.bookID); err != nil {
+			fmt.Printf("Could not update the audio cache index: %v\n", err)
+		}
+	}
+	voices, err := p.voiceChain(p.provider, p.bookID)
+	if err != nil {
+		p.mu.Unlock()
+		return err
+	}
+
+	gen := p.gen
+	runCtx, cancel := context.WithCancel(ctx)
+	p.starting = cancel
+	p.mu.Unlock()
+
+	// The first chunk is made up front so problems such as missing credentials reach the
+	// caller. It can take a while, so the lock is let go meanwhile and controls still work.
+	first, rest, err := p.firstChunk(runCtx, voices, chunks[0].Text)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.gen != gen {
+		// Stopped, or another Speak began, while the first chunk was made
+		cancel()
+		return nil
+	}
+	p.starting = nil
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to synthesize speech: %w", err)
+	}
+
+	if err := initSpeaker(); err != nil {
+		cancel()
+		return err
+	}
+
+	run := p.events.start()
+	q := &chunkQueue{
+		events: &p.events,
+		run:    run,
+		chunks: chunks,
+		audio:  make([]*chunkAudio, len(chunks)),
+		failed: make([]bool, len(chunks)),
+		wake:   make(chan struct{}, 1),
+		cancel: cancel,
+		engine: p.synth.SynthesisParams().Engine,
+	}
+	q.audio[0] = first
+
+	// A fade under way, such as the sleep timer's, carries on into the new run
+	speaker.Lock()
+	p.queue = q
+	p.ctrl = &beep.Ctrl{Streamer: q}
+	p.vol = &effects.Volume{Streamer: p.ctrl}
+	applyVolume(p.vol, p.volume*p.fade)
+	vol := p.vol
+	speaker.Unlock()
+
+	output.Play(beep.Seq(vol, beep.Callback(func() {
+		if q.stopAtBoundary && p.queue == q {
+			// The fade led up to this stop, so whatever plays next starts at full volume
+			p.resetFade()
+		}
+		q.end()
+		p.events.finish(run, nil)
+	})))
+
+	go func() {
+		if rest != nil {
+			// The first chunk is playing while it is made, and the chain makes one chunk at a time
+			made := <-rest
+			if runCtx.Err() != nil {
+				return
+			}
+			p.keep(q, 0, 0, made.audio, made.err)
+		}
+		p.synthesizeAhead(runCtx, q, voices)
+	}()
+
+	// Stop playback if the caller gives up on it
+	go func() {
+		<-runCtx.Done()
+		if err := ctx.Err(); err != nil {
+			p.events.finish(run, contextError(err))
+			p.stopRun(q)
+		}
+	}()
+
+	return nil
+}
+
+// madeChunk is the audio made for a chunk, or why it couldn't be made
+type madeChunk struct {
+	audio *chunkAudio
+	err   error
+}
+
+// firstChunk makes the audio for the first chunk of a run. Audio from a synthesizer
+// that streams is returned as soon as it starts to arrive, along with a channel that
+// gets the chunk once it is finished; otherwise the channel is nil.
+func (p *player) firstChunk(ctx context.Context, voices *voiceChain, text string) (*chunkAudio, <-chan madeChunk, error) {
+	live := make(chan *chunkAudio, 1)
+	done := make(chan madeChunk, 1)
+	go func() {
+		audio, err := voices.chunk(ctx, text, func(audio *chunkAudio) {
+			select {
+			case live <- audio:
+			default:
+			}
+		})
+		done <- madeChunk{audio: audio, err: err}
+	}()
+
+	select {
+	case audio := <-live:
+		return audio, done, nil
+	case made := <-done:
+		return made.audio, nil, made.err
+	}
+}
+
+// synthesizeAhead makes the audio for the chunks from the one playing onwards until the
+// run ends. A chunk that no engine in the chain can make is reported and skipped.
+func (p *player) synthesizeAhead(ctx context.Context, q *chunkQueue, voices *voiceChain) {
+	for {
+		speaker.Lock()
+		i := q.nextMissing()
+		settings := q.settings
+		speaker.Unlock()
+
+		if i < 0 {
+			if voices.made() {
+				p.enforceCacheLimit(voices.provider, voices.bookID)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.wake:
+				continue
+			}
+		}
+
+		audio, err := voices.chunk(ctx, q.chunks[i].Text, func(live *chunkAudio) {
+			// Playback can catch up with a chunk and play it while it's made
+			speaker.Lock()
+			if q.settings == settings && q.audio[i] == nil {
+				q.audio[i] = live
+			}
+			speaker.Unlock()
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		p.keep(q, i, settings, audio, err)
+	}
+}
+
+// keep stores the audio made for chunk i, or that it failed, unless the settings changed
+// while it was made, in which case it's made again
+func (p *player) keep(q *chunkQueue, i, settings int, audio *chunkAudio, err error) {
+	speaker.Lock()
+	if q.settings != settings {
+		speaker.Unlock()
+		return
+	}
+	if err != nil {
+		q.failed[i] = true
+	} else {
+		q.audio[i] = audio
+	}
+	speaker.Unlock()
+
+	if err != nil {
+		p.events.emit(q.run, Event{Type: EventError, Err: fmt.Errorf("failed to synthesize chunk %d/%d: %w", i+1, len(q.chunks), err)})
+	}
+}
+
+// resynthesize throws away the audio made ahead of playback, so the rest of the run is
+// made again with the synthesizer's new settings. The chunk playing carries on as it is.
+func (p *player) resynthesize() {
+	speaker.Lock()
+	defer speaker.Unlock()
+
+	q := p.queue
+	if q == nil || q.ended {
+		return
+	}
+	first := q.index
+	if q.stream != nil {
+		first++
+	}
+	for i := first; i < len(q.audio); i++ {
+		q.audio[i] = nil
+		q.failed[i] = false
+	}
+	q.settings++
+	q.wakeUp()
+}
+
+// Render synthesizes text (or reuses cached audio) and returns the audio for each chunk
+func (p *player) Render(ctx context.Context, text string) ([]AudioSegment, error) {
+	p.mu.Lock()
+	provider, bookID := p.provider, p.bookID
+	p.mu.Unlock()
+
+	book, err := p.bookAudio(provider, bookID, p.synth.SynthesisParams())
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := p.chunkText(text)
+	segments := make([]AudioSegment, 0, len(chunks))
+	for i, chunk := range chunks {
+		audio, _, err := book.chunk(ctx, chunk.Text, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to synthesize chunk %d: %w", i, err)
+		}
+		data, err := audio.read()
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, AudioSegment{
+			Format: audio.format,
+			Data:   data,
+			Text:   chunk.Text,
+			Offset: chunk.Offset,
+			Marks:  audio.marks,
+		})
+	}
+
+	if book.made {
+		p.enforceCacheLimit(provider, bookID)
+	}
+	return segments, nil
+}
+
+// Prefetch synthesizes and caches a book's audio without playing it, so the book later
+// plays from the cache. It can run for several books at once, alongside playback.
+// Rather than evict other books to make room, it stops with ErrCacheFull once the
+// cache reaches its limit, so it never pushes out the books prefetched before it.
+func (p *player) Prefetch(ctx context.Context, provider, bookID, text string, progress func(done, total int)) error {
+	release := p.cache.hold(provider, bookID)
+	defer release()
+
+	book, err := p.bookAudio(provider, bookID, p.synth.SynthesisParams())
+	if err != nil {
+		return err
+	}
+
+	chunks := p.chunkText(text)
+	for i, chunk := range chunks {
+		if !book.cached(chunk.Text) {
+			full, err := p.cache.full()
+			if err != nil {
+				return err
+			}
+			if full {
+				return ErrCacheFull
+			}
+		}
+		if _, _, err := book.chunk(ctx, chunk.Text, nil); err != nil {
+			return fmt.Errorf("failed to synthesize chunk %d: %w", i, err)
+		}
+		if progress != nil {
+			progress(i+1, len(chunks))
+		}
+	}
+	return nil
+}
+
+// enforceCacheLimit evicts old books once new audio for a book takes the cache past
+// its size limit, keeping the book the audio was made for
+func (p *player) enforceCacheLimit(provider, bookID string) {
+	evicted, err := p.cache.Enforce(provider, bookID)
+	if err != nil {
+		fmt.Printf("Could not trim the audio cache: %v\n", err)
+	}
+	for _, book := range evicted {
+		fmt.Printf("Removed cached audio for %s/%s to stay under the cache size limit\n", book.Provider, book.BookID)
+	}
+}
+
+func (p *player) Stop() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stop()
+	return nil
+}
+
+// stopRun stops playback if q is still the run playing
+func (p *player) stopRun(q *chunkQueue) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	speaker.Lock()
+	current := p.queue == q && !q.ended
+	speaker.Unlock()
+	if current {
+		p.stop()
+	}
+}
+
+// stop ends just our own stream; other sounds on the shared speaker keep playing.
+// A Speak still making its first chunk gives up. The caller holds p.mu.
+func (p *player) stop() {
+	p.gen++
+	if p.starting != nil {
+		p.starting()
+		p.starting = nil
+	}
+
+	speaker.Lock()
+	q := p.queue
+	if p.ctrl != nil {
+		p.ctrl.Streamer = nil
+	}
+	if q != nil {
+		q.end()
+	}
+	speaker.Unlock()
+
+	if q != nil {
+		p.events.finishCurrent()
+	}
+}
+
+// Subscribe returns a channel of playback events
+func (p *player) Subscribe() (<-chan Event, func()) {
+	return p.events.Subscribe()
+}
+
+func (p *player) Pause() error {
+	speaker.Lock()
+	playing := p.playing() && !p.ctrl.Paused
+	if playing {
+		p.ctrl.Paused = true
+	}
+	speaker.Unlock()
+
+	if playing {
+		p.events.emitCurrent(Event{Type: EventPaused})
+	}
+	return nil
+}
+
+func (p *player) Resume() error {
+	speaker.Lock()
+	paused := p.playing() && p.ctrl.Paused
+	if paused {
+		p.ctrl.Paused = false
+	}
+	speaker.Unlock()
+
+	if paused {
+		p.events.emitCurrent(Event{Type: EventResumed})
+	}
+	return nil
+}
+
+// playing reports whether a run is under way, paused or not. The caller holds the speaker lock.
+func (p *player) playing() bool {
+	return p.queue != nil && !p.queue.ended && p.ctrl != nil
+}
+
+func (p *player) IsPlaying() bool {
+	speaker.Lock()
+	defer speaker.Unlock()
+	return p.playing() && !p.ctrl.Paused
+}
+
+func (p *player) IsPaused() bool {
+	speaker.Lock()
+	defer speaker.Unlock()
+	return p.playing() && p.ctrl.Paused
+}
+
+func (p *player) SetVolume(volume float64) error {
+	if err := playerVolumeRange.check("volume", volume); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.volume = volume
+	speaker.Lock()
+	if p.vol != nil {
+		applyVolume(p.vol, p.volume*p.fade)
+	}
+	speaker.Unlock()
+	return nil
+}
+
+// VolumeRange is the playback volume range, where 1 plays the audio as synthesized
+func (p *player) VolumeRange() Range {
+	return playerVolumeRange
+}
+
+// speedRanger is a synthesizer that reads at a range of speeds
+type speedRanger interface {
+	SpeedRange() Range
+}
+
+// Capabilities of an engine built on the player: everything playback offers, at the
+// synthesizer's speeds. Engines whose voices can do more say so themselves.
+func (p *player) Capabilities() Capabilities {
+	caps := Capabilities{
+		Pause:     true,
+		Seek:      true,
+		Caching:   p.cache.Enabled(),
+		Streaming: true,
+		Speed:     Range{Min: 1, Max: 1},
+		Volume:    playerVolumeRange,
+	}
+	if ranged, ok := p.synth.(speedRanger); ok {
+		caps.Speed = ranged.SpeedRange()
+	}
+	return caps
+}
+
+// FadeVolume ramps the playing audio towards level over the given duration
+func (p *player) FadeVolume(level float64, over time.Duration) {
+	speaker.Lock()
+	from := p.fade
+	speaker.Unlock()
+
+	p.fader.start(from, level, over, func(l float64, current func() bool) {
+		p.mu.Lock()
+		volume := p.volume
+		p.mu.Unlock()
+
+		speaker.Lock()
+		defer speaker.Unlock()
+		if !current() {
+			// Cancelled while this step waited, e.g. by a run ending that reset the fade
+			return
+		}
+		p.fade = l
+		if p.vol != nil {
+			applyVolume(p.vol, volume*p.fade)
+		}
+	})
+}
+
+// resetFade ends any fade and goes back to full volume. The caller holds the speaker lock.
+func (p *player) resetFade() {
+	p.fader.cancel()
+	p.fade = 1.0
+}
+
+// StopAtBoundary lets the chunk playing now finish, then ends playback
+func (p *player) StopAtBoundary() {
+	speaker.Lock()
+	defer speaker.Unlock()
+	if p.queue != nil {
+		p.queue.stopAtBoundary = true
+	}
+}
+
+// Offset returns where in the spoken text playback should resume
+func (p *player) Offset() int {
+	speaker.Lock()
+	defer speaker.Unlock()
+
+	q := p.queue
+	if q == nil {
+		return 0
+	}
+	if q.index < len(q.chunks) {
+		return q.chunks[q.index].Offset
+	}
+	return q.endOffset()
+}
+
+// Position estimates where in the text playback is, from the time elapsed in the current chunk
+func (p *player) Position() Position {
+	speaker.Lock()
+	defer speaker.Unlock()
+
+	q := p.queue
+	if q == nil {
+		return Position{}
+	}
+	i := q.index
+	if i >= len(q.chunks) {
+		return Position{Chunk: i, Offset: q.endOffset()}
+	}
+
+	chunk := q.chunks[i]
+	if q.stream == nil {
+		return Position{Chunk: i, Offset: chunk.Offset + q.seekRel}
+	}
+	elapsed := q.format.SampleRate.D(q.stream.Position())
+	length := q.format.SampleRate.D(q.stream.Len())
+	return Position{Chunk: i, Offset: chunk.Offset + offsetInChunk(chunk, q.audio[i].marks, elapsed, length)}
+}
+
+// Seek carries on playing from offset. Audio that isn't ready yet is synthesized
+// first, with silence until it is.
+func (p *player) Seek(offset int) error {
+	speaker.Lock()
+	defer speaker.Unlock()
+
+	if !p.playing() {
+		return fmt.Errorf("nothing is playing")
+	}
+
+	q := p.queue
+	q.closeStream()
+	i := chunkIndexAt(q.chunks, offset)
+	q.index = i
+	q.seekRel = 0
+	if i < len(q.chunks) && offset > q.chunks[i].Offset {
+		q.seekRel = offset - q.chunks[i].Offset
+	}
+	q.wakeUp()
+	return nil
+}
+
+// Spoken returns the byte range of the word being spoken when the chunk has word
+// timings, otherwise of the whole chunk
+func (p *player) Spoken() (int, int, bool) {
+	speaker.Lock()
+	defer speaker.Unlock()
+
+	if !p.playing() {
+		return 0, 0, false
+	}
+	q := p.queue
+	i := q.index
+	if i >= len(q.chunks) {
+		return 0, 0, false
+	}
+	chunk := q.chunks[i]
+
+	if q.stream != nil && len(q.audio[i].marks) > 0 {
+		marks := q.audio[i].marks
+		elapsed := q.format.SampleRate.D(q.stream.Position())
+		word := -1
+		for j, m := range marks {
+			if m.Time > elapsed {
+				break
+			}
+			word = j
+		}
+		if word >= 0 {
+			start := chunk.Offset + marks[word].Offset
+			return start, start + len(wordAt(chunk.Text, marks[word].Offset)), true
+		}
+	}
+
+	return chunk.Offset, chunk.Offset + len(chunk.Text), true
+}
+
+// GetCacheStats describes the audio cache
+func (p *player) GetCacheStats() (map[string]interface{}, error) {
+	books, err := p.cache.Books()
+	if err != nil {
+		return nil, err
+	}
+
+	var files int
+	var size int64
+	for _, book := range books {
+		files += book.Files
+		size += book.Bytes
+	}
+	return map[string]interface{}{
+		"cache_directory": p.cache.Root(),
+		"cached_files":    files,
+		"total_size_mb":   float64(size) / (1024 * 1024),
+	}, nil
+}
+
+// ClearCache removes all cached audio
+func (p *player) ClearCache() error {
+	return p.cache.Clear()
+}
+
+// chunkQueue streams the chunks of one run back to back as their audio becomes ready.
+// The speaker streams it, so its fields are guarded by the speaker lock.
+type chunkQueue struct {
+	events *eventHub
+	run    int
+	chunks []textChunk
+	audio  []*chunkAudio // nil until synthesized
+	failed []bool        // synthesis failed, so the chunk is skipped
+
+	index          int // chunk playing, or waiting for its audio
+	seekRel        int // byte offset in the chunk to start from once it opens
+	stream         beep.StreamSeekCloser
+	format         beep.Format
+	resampled      beep.Streamer
+	stopAtBoundary bool
+	ended          bool
+
+	wake     chan struct{} // tells the synthesis loop playback has moved
+	settings int           // counts changes to the synthesizer's settings, so audio made with old ones is thrown away
+	cancel   context.CancelFunc
+	engine   string // engine that made the audio last played
+}
+
+func (q *chunkQueue) Stream(samples [][2]float64) (int, bool) {
+	filled := 0
+	for filled < len(samples) {
+		if q.ended || q.index >= len(q.chunks) {
+			break
+		}
+
+		if q.stream == nil {
+			if q.failed[q.index] {
+				q.next()
+				continue
+			}
+			if audio := q.audio[q.index]; audio == nil || q.seekRel > 0 && !audio.ready() {
+				// Still being synthesized, or too little of it to seek in yet: play
+				// silence rather than hold up the speaker
+				for i := filled; i < len(samples); i++ {
+					samples[i] = [2]float64{}
+				}
+				return len(samples), true
+			}
+			if err := q.open(); err != nil {
+				q.events.emit(q.run, Event{Type: EventError, Err: err})
+				q.next()
+				continue
+			}
+		}
+
+		n, ok := q.resampled.Stream(samples[filled:])
+		filled += n
+		if !ok || n == 0 {
+			q.next()
+		}
+	}
+
+	if filled == 0 {
+		return 0, false
+	}
+	return filled, true
+}
+
+func (q *chunkQueue) Err() error {
+	return nil
+}
+
+// open decodes the chunk at index, seeks to where a Seek asked for and announces it
+func (q *chunkQueue) open() error {
+	audio := q.audio[q.index]
+	stream, format, err := audio.open()
+	if err != nil {
+		return fmt.Errorf("failed to play chunk %d: %w", q.index+1, err)
+	}
+
+	if q.seekRel > 0 {
+		chunk := q.chunks[q.index]
+		at := timeInChunk(chunk, audio.marks, q.seekRel, format.SampleRate.D(stream.Len()))
+		if err := stream.Seek(format.SampleRate.N(at)); err != nil {
+			stream.Close()
+			return fmt.Errorf("failed to seek in chunk %d: %w", q.index+1, err)
+		}
+		q.seekRel = 0
+	}
+
+	q.stream = stream
+	q.format = format
+	q.resampled = toOutputRate(stream, format.SampleRate)
+	if audio.engine != q.engine {
+		q.events.emit(q.run, Event{Type: EventEngineSwitched, Engine: audio.engine, Err: audio.switched})
+		q.engine = audio.engine
+	}
+	q.events.chunk(q.run, q.index, q.chunks)
+	return nil
+}
+
+// next moves on to the following chunk, or ends the run if it was asked to stop here
+func (q *chunkQueue) next() {
+	q.closeStream()
+	q.index++
+	q.seekRel = 0
+	if q.stopAtBoundary {
+		q.ended = true
+	}
+	q.wakeUp()
+}
+
+// nextMissing returns the first chunk from the one playing onwards that needs audio, or -1
+func (q *chunkQueue) nextMissing() int {
+	if q.ended {
+		return -1
+	}
+	for i := q.index; i < len(q.chunks); i++ {
+		if q.audio[i] == nil && !q.failed[i] {
+			return i
+		}
+	}
+	return -1
+}
+
+func (q *chunkQueue) closeStream() {
+	if q.stream != nil {
+		q.stream.Close()
+		q.stream = nil
+		q.resampled = nil
+	}
+}
+
+// end finishes the run: playback stops and so does synthesis
+func (q *chunkQueue) end() {
+	q.ended = true
+	q.closeStream()
+	q.cancel()
+}
+
+func (q *chunkQueue) wakeUp() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// endOffset is the offset just past the last chunk
+func (q *chunkQueue) endOffset() int {
+	if len(q.chunks) == 0 {
+		return 0
+	}
+	last := q.chunks[len(q.chunks)-1]
+	return last.Offset + len(last.Text)
+}
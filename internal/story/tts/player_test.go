@@ -0,0 +1,291 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+)
+
+// testOutput stands in for the audio device. Nothing plays until the test pulls
+// samples through it, so playback moves on exactly when the test says.
+type testOutput struct {
+	mixer beep.Mixer
+}
+
+// useTestOutput plays through a testOutput for the rest of the test
+func useTestOutput(t *testing.T) *testOutput {
+	out := &testOutput{}
+	saved := output
+	output = out
+	t.Cleanup(func() { output = saved })
+	return out
+}
+
+func (o *testOutput) Init() error {
+	return nil
+}
+
+func (o *testOutput) Play(s beep.Streamer) {
+	speaker.Lock()
+	defer speaker.Unlock()
+	o.mixer.Add(s)
+}
+
+// pull plays d of audio, as the device would
+func (o *testOutput) pull(d time.Duration) {
+	samples := make([][2]float64, outputSampleRate.N(d))
+	speaker.Lock()
+	defer speaker.Unlock()
+	o.mixer.Stream(samples)
+}
+
+// playUntil pulls audio until an event of the given type arrives
+func (o *testOutput) playUntil(t *testing.T, events <-chan Event, want EventType) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		select {
+		case ev := <-events:
+			if ev.Type == want {
+				return
+			}
+		default:
+			if time.Now().After(deadline) {
+				t.Fatalf("no %v event", want)
+			}
+			o.pull(100 * time.Millisecond)
+			// Let synthesis in the background catch up
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+func TestSpeedChangeRemakesLaterChunks(t *testing.T) {
+	out := useTestOutput(t)
+	synth := &fakeSynth{limit: 20, pcm: 48000, speed: 1} // a second per chunk
+	p := synth.player(NewAudioCache(t.TempDir(), 0, true))
+
+	events, unsubscribe := p.Subscribe()
+	defer unsubscribe()
+
+	chunks := []string{"The first part.", "The second part.", "The third part.", "The last part."}
+	text := ""
+	for _, chunk := range chunks {
+		text += chunk + " "
+	}
+	if err := p.SpeakContext(context.Background(), text); err != nil {
+		t.Fatalf("SpeakContext: %v", err)
+	}
+	out.playUntil(t, events, EventChunkStarted)
+
+	// Once the chunks ahead are made, they have to be made again at the new speed
+	deadline := time.Now().Add(10 * time.Second)
+	for made := 0; made < len(chunks); {
+		if time.Now().After(deadline) {
+			t.Fatal("the chunks ahead were never made")
+		}
+		time.Sleep(time.Millisecond)
+		made = synth.texts()
+	}
+	synth.setSpeed(2)
+	p.resynthesize()
+
+	out.playUntil(t, events, EventFinished)
+
+	if got := synth.madeWith(chunks[0]).Speed; got != 1 {
+		t.Errorf("the part playing was made at speed %v, want 1", got)
+	}
+	for _, chunk := range chunks[1:] {
+		if got := synth.madeWith(chunk).Speed; got != 2 {
+			t.Errorf("%q was last made at speed %v, want 2", chunk, got)
+		}
+	}
+}
+
+func TestStreamedChunkPlaysWhileItIsMade(t *testing.T) {
+	out := useTestOutput(t)
+	// The test writes the PCM into the pipe each chunk streams through
+	writers := make(chan *io.PipeWriter, 1)
+	synth := &fakeSynth{stream: func(text string) (io.ReadCloser, error) {
+		r, w := io.Pipe()
+		writers <- w
+		return r, nil
+	}}
+	p := synth.player(NewAudioCache(t.TempDir(), 0, true))
+	p.SetBookContext("test", "book")
+
+	events, unsubscribe := p.Subscribe()
+	defer unsubscribe()
+
+	spoke := make(chan error, 1)
+	go func() {
+		spoke <- p.SpeakContext(context.Background(), "Once upon a time.")
+	}()
+
+	timeout := time.After(10 * time.Second)
+	var w *io.PipeWriter
+	select {
+	case w = <-writers:
+	case <-timeout:
+		t.Fatal("the chunk was never synthesized")
+	}
+	w.Write(make([]byte, 4800))
+
+	// Playback starts on the first of the audio, before the rest has been made
+	select {
+	case err := <-spoke:
+		if err != nil {
+			t.Fatalf("SpeakContext: %v", err)
+		}
+	case <-timeout:
+		t.Fatal("SpeakContext waited for the whole chunk")
+	}
+	out.pull(50 * time.Millisecond) // half the audio made so far
+	for started := false; !started; {
+		select {
+		case ev := <-events:
+			started = ev.Type == EventChunkStarted
+		case <-timeout:
+			t.Fatal("the chunk didn't start playing while it was made")
+		}
+	}
+
+	w.Write(make([]byte, 4800))
+	w.Close()
+	out.playUntil(t, events, EventFinished)
+
+	// All of it is cached once it is made
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		book, err := p.bookAudio("test", "book", synth.SynthesisParams())
+		if err != nil {
+			t.Fatalf("bookAudio: %v", err)
+		}
+		if book.cached("Once upon a time.") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the streamed chunk wasn't cached")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	book, err := p.bookAudio("test", "book", synth.SynthesisParams())
+	if err != nil {
+		t.Fatalf("bookAudio: %v", err)
+	}
+	audio, _, err := book.chunk(context.Background(), "Once upon a time.", nil)
+	if err != nil {
+		t.Fatalf("chunk: %v", err)
+	}
+	data, err := audio.read()
+	if err != nil {
+		t.Fatalf("reading the cached audio: %v", err)
+	}
+	if got := len(data) - 44; got != 9600 {
+		t.Errorf("cached %d bytes of PCM, want 9600", got)
+	}
+}
+
+func TestPrefetchStopsWhenCacheIsFull(t *testing.T) {
+	cache := NewAudioCache(t.TempDir(), 1, true)
+	p := (&fakeSynth{limit: 20, pcm: 400 * 1024}).player(cache)
+
+	if err := p.Prefetch(context.Background(), "test", "first", "The first part. The second part.", nil); err != nil {
+		t.Fatalf("prefetching the first book: %v", err)
+	}
+	err := p.Prefetch(context.Background(), "test", "second", "The first part. The second part.", nil)
+	if !errors.Is(err, ErrCacheFull) {
+		t.Fatalf("prefetching past the limit returned %v, want ErrCacheFull", err)
+	}
+
+	books, err := cache.Books()
+	if err != nil {
+		t.Fatalf("Books: %v", err)
+	}
+	var ids []string
+	for _, book := range books {
+		ids = append(ids, book.BookID)
+	}
+	if !slices.Contains(ids, "first") {
+		t.Errorf("the book prefetched earlier was evicted, cached books are %v", ids)
+	}
+}
+
+func TestHeldBookIsNeverEvicted(t *testing.T) {
+	cache := NewAudioCache(t.TempDir(), 1, true)
+	p := (&fakeSynth{limit: 20, pcm: 400 * 1024}).player(cache)
+	// The book being prefetched is the least recently used, so it would go first
+	for _, id := range []string{"prefetching", "newer"} {
+		book, err := p.bookAudio("test", id, p.synth.SynthesisParams())
+		if err != nil {
+			t.Fatalf("bookAudio: %v", err)
+		}
+		for _, text := range []string{"The first part.", "The second part."} {
+			if _, _, err := book.chunk(context.Background(), text, nil); err != nil {
+				t.Fatalf("making %s: %v", id, err)
+			}
+		}
+	}
+
+	release := cache.hold("test", "prefetching")
+	defer release()
+	evicted, err := cache.Enforce("test", "playing")
+	if err != nil {
+		t.Fatalf("Enforce: %v", err)
+	}
+	for _, book := range evicted {
+		if book.BookID == "prefetching" {
+			t.Error("the book being prefetched was evicted")
+		}
+	}
+	if len(evicted) != 1 || evicted[0].BookID != "newer" {
+		t.Errorf("evicted %v, want just the newer book", evicted)
+	}
+}
+
+func TestFadeCarriesIntoNextRun(t *testing.T) {
+	useTestOutput(t)
+	p := (&fakeSynth{}).player(NewAudioCache(t.TempDir(), 0, true))
+
+	if err := p.SpeakContext(context.Background(), "The first part."); err != nil {
+		t.Fatalf("SpeakContext: %v", err)
+	}
+	p.FadeVolume(0.2, 0)
+
+	// e.g. restarting the story, or the next one in the queue, while the sleep timer fades out
+	if err := p.SpeakContext(context.Background(), "The second part."); err != nil {
+		t.Fatalf("SpeakContext: %v", err)
+	}
+	speaker.Lock()
+	fade := p.fade
+	speaker.Unlock()
+	if fade != 0.2 {
+		t.Errorf("the next run played at fade %v, want 0.2", fade)
+	}
+}
+
+func TestFadeStepDoesNotUndoReset(t *testing.T) {
+	p := (&fakeSynth{}).player(NewAudioCache(t.TempDir(), 0, true))
+	p.FadeVolume(0, 10*fadeStep)
+
+	// A step fires while the run that stopped at the boundary resets the fade
+	speaker.Lock()
+	time.Sleep(2 * fadeStep)
+	p.resetFade()
+	speaker.Unlock()
+	time.Sleep(fadeStep / 2)
+
+	speaker.Lock()
+	fade := p.fade
+	speaker.Unlock()
+	if fade != 1 {
+		t.Errorf("fade = %v after the reset, want 1", fade)
+	}
+}
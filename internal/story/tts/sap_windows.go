@@ -3,244 +3,162 @@
 package tts
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"os/exec"
 	"regexp"
 	"strings"
 	"sync"
-	"time"
 )
 
-// SAPIEngine implements Windows SAPI TTS
+// SAPIEngine synthesizes WAV with the Windows Speech API and plays it through the shared player
 type SAPIEngine struct {
-	config  Config
-	voice   uintptr
-	playing bool
-	paused  bool
-	mutex   sync.RWMutex
+	*player
+	voice string
+	speed float64
+	mutex sync.RWMutex
 }
 
-func (s *SAPIEngine) SetBookContext(provider, bookID string) {
-	//TODO implement me
-	panic("implement me")
-}
+// sapiChunkLimit keeps each PowerShell command well inside the command line length limit
+const sapiChunkLimit = 2000
+
+// sapiSpeedRange maps onto SAPI's rates from -10 to 10
+var sapiSpeedRange = Range{Min: 0.1, Max: 3.0}
 
 // newSAPIEngine creates a new Windows SAPI TTS engine
 func newSAPIEngine(config Config) (*SAPIEngine, error) {
 	engine := &SAPIEngine{
-		config: config,
+		voice: config.Voice,
+		speed: config.Speed,
 	}
+	if engine.speed <= 0 {
+		engine.speed = 1.0
+	}
+	engine.player = newPlayer(engine, EngineTypeSAPI.String(), OpenAudioCache(), config.Volume)
 
 	return engine, nil
 }
 
-func (s *SAPIEngine) Speak(text string) error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	if s.playing {
-		return fmt.Errorf("already playing")
-	}
-
-	s.playing = true
-
-	// Simulate async speech
-	go func() {
-		defer func() {
-			s.mutex.Lock()
-			s.playing = false
-			s.paused = false
-			s.mutex.Unlock()
-		}()
-
-		// Chunk the text to avoid command line length limits
-		chunks := s.chunkText(text, 500) // 500 words per chunk
-
-		for i, chunk := range chunks {
-			// Check if we should stop
-			s.mutex.RLock()
-			shouldStop := !s.playing
-			s.mutex.RUnlock()
-
-			if shouldStop {
-				break
-			}
-
-			// Escape quotes and special characters in the text
-			escapedChunk := s.escapeForPowerShell(chunk)
-
-			// Use PowerShell to access Windows Speech API
-			cmd := exec.Command("powershell", "-Command",
-				fmt.Sprintf(`Add-Type -AssemblyName System.Speech; 
-				$synth = New-Object System.Speech.Synthesis.SpeechSynthesizer; 
-				$synth.Rate = %d; 
-				$synth.Volume = %d; 
-				$synth.Speak('%s')`,
-					int(s.config.Speed*10)-10, // Convert to SAPI range (-10 to 10)
-					int(s.config.Volume*100),  // Convert to SAPI range (0 to 100)
-					escapedChunk))
-
-			if err := cmd.Run(); err != nil {
-				fmt.Printf("SAPI error (chunk %d/%d): %v\n", i+1, len(chunks), err)
-				// Continue with next chunk instead of stopping entirely
-			}
-
-			// Small pause between chunks to avoid overwhelming the system
-			if i < len(chunks)-1 { // Don't pause after the last chunk
-				time.Sleep(100 * time.Millisecond)
-			}
-		}
-	}()
-
-	return nil
+// SynthesisParams are the voice and speed new audio is made with
+func (s *SAPIEngine) SynthesisParams() SynthesisParams {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return SynthesisParams{Engine: EngineTypeSAPI.String(), Voice: s.voice, Speed: s.speed, ChunkerVersion: chunkerVersion}
 }
 
-func (s *SAPIEngine) Stop() error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	// Kill any running PowerShell processes that might be running SAPI
-	exec.Command("taskkill", "/F", "/IM", "powershell.exe", "/T").Run()
-
-	s.playing = false
-	s.paused = false
-	return nil
+func (s *SAPIEngine) ChunkLimit() int {
+	return sapiChunkLimit
 }
 
-func (s *SAPIEngine) Pause() error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
+func (s *SAPIEngine) AudioFormat() string {
+	return "wav"
+}
 
-	if !s.playing || s.paused {
-		return nil
+// Synthesize has SAPI write a chunk to a WAV file and returns its contents
+func (s *SAPIEngine) Synthesize(ctx context.Context, text string, params SynthesisParams) ([]byte, []WordMark, error) {
+	out, err := os.CreateTemp("", "storynest-sapi-*.wav")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create temporary WAV file: %w", err)
 	}
+	out.Close()
+	defer os.Remove(out.Name())
 
-	s.paused = true
-	return nil
-}
-
-func (s *SAPIEngine) Resume() error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
+	selectVoice := ""
+	if params.Voice != "" && params.Voice != "default" {
+		selectVoice = fmt.Sprintf("$synth.SelectVoice('%s');", s.escapeForPowerShell(params.Voice))
+	}
 
-	if !s.paused {
-		return nil
+	// Use PowerShell to access Windows Speech API
+	cmd := exec.CommandContext(ctx, "powershell", "-NoProfile", "-Command",
+		fmt.Sprintf(`Add-Type -AssemblyName System.Speech; 
+		$synth = New-Object System.Speech.Synthesis.SpeechSynthesizer; 
+		%s
+		$synth.Rate = %d; 
+		$synth.SetOutputToWaveFile('%s'); 
+		$synth.Speak('%s'); 
+		$synth.Dispose()`,
+			selectVoice,
+			int(params.Speed*10)-10, // Convert to SAPI range (-10 to 10)
+			strings.ReplaceAll(out.Name(), "'", "''"),
+			s.escapeForPowerShell(text)))
+	if err := cmd.Run(); err != nil {
+		return nil, nil, fmt.Errorf("SAPI failed: %w", err)
 	}
 
-	s.paused = false
-	return nil
+	data, err := os.ReadFile(out.Name())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read SAPI audio: %w", err)
+	}
+	return data, nil, nil
 }
 
 func (s *SAPIEngine) SetVoice(voice string) error {
 	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	s.config.Voice = voice
+	s.voice = voice
+	s.mutex.Unlock()
+	s.resynthesize()
 	return nil
 }
 
 func (s *SAPIEngine) SetSpeed(speed float64) error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	if speed <= 0 || speed > 3.0 {
-		return fmt.Errorf("speed must be between 0.1 and 3.0")
+	if err := sapiSpeedRange.check("speed", speed); err != nil {
+		return err
 	}
 
-	s.config.Speed = speed
-	return nil
-}
-
-func (s *SAPIEngine) SetVolume(volume float64) error {
 	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	if volume < 0 || volume > 1.0 {
-		return fmt.Errorf("volume must be between 0 and 1.0")
-	}
-
-	s.config.Volume = volume
+	s.speed = speed
+	s.mutex.Unlock()
+	s.resynthesize()
 	return nil
 }
 
-func (s *SAPIEngine) IsPlaying() bool {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-	return s.playing && !s.paused
-}
-
-func (s *SAPIEngine) IsPaused() bool {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-	return s.paused
+func (s *SAPIEngine) SpeedRange() Range {
+	return sapiSpeedRange
 }
 
 func (s *SAPIEngine) GetAvailableVoices() ([]string, error) {
-	return []string{"Microsoft David", "Microsoft Zira", "Microsoft Mark"}, nil
-}
-
-// chunkText splits text into smaller chunks suitable for SAPI
-func (s *SAPIEngine) chunkText(text string, maxWords int) []string {
-	words := strings.Fields(text)
-	if len(words) <= maxWords {
-		return []string{text}
+	voices, err := s.GetVoiceInfo()
+	if err != nil {
+		return nil, err
 	}
-
-	var chunks []string
-	var currentChunk []string
-
-	for _, word := range words {
-		currentChunk = append(currentChunk, word)
-
-		// Check if we should split at a sentence boundary
-		if len(currentChunk) >= maxWords {
-			// Look for a good breaking point (sentence end)
-			chunkText := strings.Join(currentChunk, " ")
-			if breakPoint := s.findSentenceBreak(chunkText, len(currentChunk)*3/4); breakPoint > 0 {
-				// Split at sentence boundary
-				chunk := chunkText[:breakPoint]
-				remainder := strings.TrimSpace(chunkText[breakPoint:])
-
-				chunks = append(chunks, chunk)
-
-				// Start next chunk with remainder
-				if remainder != "" {
-					currentChunk = strings.Fields(remainder)
-				} else {
-					currentChunk = []string{}
-				}
-			} else {
-				// No good break point, split at word boundary
-				chunks = append(chunks, strings.Join(currentChunk, " "))
-				currentChunk = []string{}
-			}
-		}
+	return voiceNames(voices), nil
+}
+
+// GetVoiceInfo asks System.Speech for the voices installed on this machine
+func (s *SAPIEngine) GetVoiceInfo() ([]VoiceInfo, error) {
+	cmd := exec.Command("powershell", "-NoProfile", "-Command",
+		`Add-Type -AssemblyName System.Speech;
+		(New-Object System.Speech.Synthesis.SpeechSynthesizer).GetInstalledVoices() |
+		Where-Object { $_.Enabled } |
+		ForEach-Object { $v = $_.VoiceInfo; '{0}|{1}|{2}|{3}' -f $v.Name, $v.Culture.Name, $v.Gender, $v.Description }`)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SAPI voices: %w", err)
 	}
 
-	// Add remaining words
-	if len(currentChunk) > 0 {
-		chunks = append(chunks, strings.Join(currentChunk, " "))
-	}
-
-	return chunks
+	voices := parseSAPIVoices(string(output))
+	sortVoices(voices)
+	return voices, nil
 }
 
-// findSentenceBreak finds the best place to break text at a sentence boundary
-func (s *SAPIEngine) findSentenceBreak(text string, minPos int) int {
-	// Look for sentence endings after the minimum position
-	sentenceEnders := []string{". ", "! ", "? ", ".\n", "!\n", "?\n"}
-
-	bestPos := -1
-	for _, ender := range sentenceEnders {
-		if pos := strings.Index(text[minPos:], ender); pos != -1 {
-			actualPos := minPos + pos + len(ender)
-			if bestPos == -1 || actualPos < bestPos {
-				bestPos = actualPos
-			}
+// parseSAPIVoices reads the Name|Culture|Gender|Description lines printed by GetVoiceInfo
+func parseSAPIVoices(output string) []VoiceInfo {
+	var voices []VoiceInfo
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), "|", 4)
+		if len(fields) < 4 || fields[0] == "" {
+			continue
 		}
+		voices = append(voices, VoiceInfo{
+			Name:         fields[0],
+			LanguageCode: languageTag(fields[1]),
+			Gender:       genderName(fields[2]),
+			Natural:      strings.Contains(fields[0], "Natural"),
+			Description:  fields[3],
+		})
 	}
-
-	return bestPos
+	return voices
 }
 
 // escapeForPowerShell escapes special characters for PowerShell command execution
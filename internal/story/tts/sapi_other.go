@@ -0,0 +1,10 @@
+//go:build !windows
+
+package tts
+
+import "fmt"
+
+// newSAPIEngine explains that SAPI is only on Windows
+func newSAPIEngine(config Config) (Engine, error) {
+	return nil, fmt.Errorf("SAPI engine only supports Windows")
+}
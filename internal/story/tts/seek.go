@@ -0,0 +1,111 @@
+package tts
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Position is where playback is in the text passed to Speak
+type Position struct {
+	Chunk  int // index of the chunk being spoken
+	Offset int // byte offset of the current point in the text
+}
+
+// SentenceStarts returns the byte offset where each sentence of text begins
+func SentenceStarts(text string) []int {
+	var starts []int
+	for _, s := range splitSentences(text) {
+		if start, ok := firstNonSpace(text, s.start, s.end); ok {
+			starts = append(starts, start)
+		}
+	}
+	return starts
+}
+
+// ParagraphStarts returns the byte offset where each paragraph of text begins
+func ParagraphStarts(text string) []int {
+	var starts []int
+	newParagraph := true
+	for _, s := range splitSentences(text) {
+		if newParagraph {
+			if start, ok := firstNonSpace(text, s.start, s.end); ok {
+				starts = append(starts, start)
+			}
+		}
+		newParagraph = s.paragraphEnd
+	}
+	return starts
+}
+
+func firstNonSpace(text string, start, end int) (int, bool) {
+	trimmed := strings.TrimLeftFunc(text[start:end], unicode.IsSpace)
+	if trimmed == "" {
+		return 0, false
+	}
+	return end - len(trimmed), true
+}
+
+// chunkIndexAt returns the chunk holding offset, or the nearest one after it
+func chunkIndexAt(chunks []textChunk, offset int) int {
+	for i, c := range chunks {
+		if offset < c.Offset+len(c.Text) {
+			return i
+		}
+	}
+	return len(chunks)
+}
+
+// timeInChunk estimates how far into a chunk's audio the text at rel (a byte offset
+// into the chunk text) is spoken, from word marks if there are any, otherwise by length
+func timeInChunk(chunk textChunk, marks []WordMark, rel int, length time.Duration) time.Duration {
+	if rel <= 0 {
+		return 0
+	}
+	if len(marks) > 0 {
+		var t time.Duration
+		for _, m := range marks {
+			if m.Offset > rel {
+				break
+			}
+			t = m.Time
+		}
+		return t
+	}
+	total := utf8.RuneCountInString(chunk.Text)
+	if total == 0 || rel >= len(chunk.Text) {
+		return length
+	}
+	return proportion(length, chunk.Text[:rel], total)
+}
+
+// offsetInChunk is the inverse of timeInChunk: the byte offset in the chunk text
+// being spoken at elapsed
+func offsetInChunk(chunk textChunk, marks []WordMark, elapsed, length time.Duration) int {
+	if len(marks) > 0 {
+		rel := 0
+		for _, m := range marks {
+			if m.Time > elapsed {
+				break
+			}
+			rel = m.Offset
+		}
+		return rel
+	}
+	if length <= 0 || elapsed <= 0 {
+		return 0
+	}
+	if elapsed >= length {
+		return len(chunk.Text)
+	}
+
+	// Walk forward to the rune at the same fraction of the text
+	target := int(float64(utf8.RuneCountInString(chunk.Text)) * float64(elapsed) / float64(length))
+	rel := 0
+	for i := 0; i < target && rel < len(chunk.Text); i++ {
+		_, size := utf8.DecodeRuneInString(chunk.Text[rel:])
+		rel += size
+	}
+	return rel
+}
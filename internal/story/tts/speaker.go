@@ -0,0 +1,56 @@
+package tts
+
+import (
+	"sync"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+)
+
+// outputSampleRate is the rate the shared speaker runs at; streams at other rates are resampled
+const outputSampleRate = beep.SampleRate(44100)
+
+var (
+	speakerOnce sync.Once
+	speakerErr  error
+)
+
+// output is where speech and background sounds are played. Tests swap it for one
+// they pull the samples from themselves, so they need no audio device.
+var output audioOutput = deviceOutput{}
+
+// audioOutput mixes the streams played on it, streaming them under the speaker lock
+type audioOutput interface {
+	// Init gets the output ready to play; it can be called more than once
+	Init() error
+	Play(s beep.Streamer)
+}
+
+// deviceOutput is the audio device. It is opened once for the whole process, so speech
+// and background sounds play through the same mixer without resetting each other.
+type deviceOutput struct{}
+
+func (deviceOutput) Init() error {
+	speakerOnce.Do(func() {
+		speakerErr = speaker.Init(outputSampleRate, outputSampleRate.N(time.Second/10))
+	})
+	return speakerErr
+}
+
+func (deviceOutput) Play(s beep.Streamer) {
+	speaker.Play(s)
+}
+
+// initSpeaker gets the output ready to play
+func initSpeaker() error {
+	return output.Init()
+}
+
+// toOutputRate resamples a stream to the speaker's sample rate if needed
+func toOutputRate(s beep.Streamer, from beep.SampleRate) beep.Streamer {
+	if from == outputSampleRate {
+		return s
+	}
+	return beep.Resample(4, from, outputSampleRate, s)
+}
@@ -0,0 +1,495 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/mp3"
+	"github.com/faiface/beep/wav"
+)
+
+// Synthesizer turns text into audio. Engines built on one share the player, which
+// chunks the text, caches the audio and plays it back the same way for every engine.
+type Synthesizer interface {
+	// SynthesisParams are the settings new audio is made with. Cached audio made
+	// with different settings is made again.
+	SynthesisParams() SynthesisParams
+	// ChunkLimit is the most text, in runes, to synthesize in one go
+	ChunkLimit() int
+	// AudioFormat is the format Synthesize returns: "mp3" or "wav"
+	AudioFormat() string
+	// Synthesize makes the audio for one chunk of text, with the time of each word if the engine knows them
+	Synthesize(ctx context.Context, text string, params SynthesisParams) ([]byte, []WordMark, error)
+}
+
+// chunkMeasurer is a Synthesizer whose ChunkLimit isn't counted in runes, e.g. because
+// the limit is on the request the text is sent in
+type chunkMeasurer interface {
+	// ChunkSize measures text against ChunkLimit
+	ChunkSize(text string) int
+}
+
+// pcmStreamer is a Synthesizer that hands over its audio while it is being made, so
+// playback can start before a chunk is finished
+type pcmStreamer interface {
+	// SynthesizeStream starts making the audio for a chunk and returns its raw 16-bit
+	// mono PCM as it is made, with the sample rate. Closing it waits for synthesis to
+	// end and reports whether it failed.
+	SynthesizeStream(ctx context.Context, text string, params SynthesisParams) (io.ReadCloser, int, error)
+}
+
+// chunkAudio is the synthesized audio for one chunk of text: a file in the cache, or
+// data in memory for text that isn't part of a book
+type chunkAudio struct {
+	format   string
+	path     string
+	data     []byte
+	marks    []WordMark
+	engine   string     // engine that made it
+	switched error      // why the engine before it in the chain failed, on the first chunk a fallback made
+	live     *liveAudio // audio still being made, played as it arrives
+}
+
+// read returns the encoded audio
+func (a *chunkAudio) read() ([]byte, error) {
+	if a.path == "" {
+		return a.data, nil
+	}
+	data, err := os.ReadFile(a.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cached audio %s: %w", a.path, err)
+	}
+	return data, nil
+}
+
+// open decodes the audio for playback
+func (a *chunkAudio) open() (beep.StreamSeekCloser, beep.Format, error) {
+	if a.live != nil {
+		return &liveStreamer{live: a.live}, a.live.format(), nil
+	}
+
+	var r io.ReadCloser = memoryFile{bytes.NewReader(a.data)}
+	if a.path != "" {
+		f, err := os.Open(a.path)
+		if err != nil {
+			return nil, beep.Format{}, fmt.Errorf("failed to open cached audio %s: %w", a.path, err)
+		}
+		r = f
+	}
+
+	streamer, format, err := decodeAudio(a.format, r)
+	if err != nil {
+		r.Close()
+		return nil, beep.Format{}, err
+	}
+	return streamer, format, nil
+}
+
+// ready reports whether all the audio is there, so its length is known
+func (a *chunkAudio) ready() bool {
+	return a.live == nil || a.live.complete()
+}
+
+// memoryFile lets audio held in memory be decoded and seeked like a file
+type memoryFile struct {
+	*bytes.Reader
+}
+
+func (memoryFile) Close() error {
+	return nil
+}
+
+// decodeAudio decodes MP3 or WAV audio. Seeking works when r is also an io.Seeker.
+func decodeAudio(format string, r io.ReadCloser) (beep.StreamSeekCloser, beep.Format, error) {
+	switch format {
+	case "mp3":
+		streamer, f, err := mp3.Decode(r)
+		if err != nil {
+			return nil, beep.Format{}, fmt.Errorf("failed to decode MP3: %w", err)
+		}
+		return streamer, f, nil
+	case "wav":
+		// Read it all so placeholder sizes can be fixed before the header is trusted
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return nil, beep.Format{}, fmt.Errorf("failed to read WAV: %w", err)
+		}
+		streamer, f, err := wav.Decode(memoryFile{bytes.NewReader(FixWAVSizes(data))})
+		if err != nil {
+			return nil, beep.Format{}, fmt.Errorf("failed to decode WAV: %w", err)
+		}
+		return streamer, f, nil
+	default:
+		return nil, beep.Format{}, fmt.Errorf("unsupported audio format %q", format)
+	}
+}
+
+// audioDuration is how long some audio plays for, or 0 if it can't be decoded
+func audioDuration(format string, data []byte) time.Duration {
+	streamer, f, err := decodeAudio(format, memoryFile{bytes.NewReader(data)})
+	if err != nil {
+		return 0
+	}
+	defer streamer.Close()
+	return f.SampleRate.D(streamer.Len())
+}
+
+// FixWAVSizes patches the RIFF and data chunk sizes of a WAV captured from a pipe.
+// Tools writing to stdout can't seek back, so they leave placeholder sizes in the header.
+func FixWAVSizes(data []byte) []byte {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return data
+	}
+
+	fixed := append([]byte(nil), data...)
+	binary.LittleEndian.PutUint32(fixed[4:8], uint32(len(fixed)-8))
+
+	for pos := 12; pos+8 <= len(fixed); {
+		id := string(fixed[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(fixed[pos+4 : pos+8]))
+		if id == "data" {
+			binary.LittleEndian.PutUint32(fixed[pos+4:pos+8], uint32(len(fixed)-pos-8))
+			break
+		}
+		pos += 8 + size + size%2
+	}
+
+	return fixed
+}
+
+// pcmToWAV wraps raw signed 16-bit little-endian PCM in a WAV header
+func pcmToWAV(pcm []byte, sampleRate, channels int) []byte {
+	var b bytes.Buffer
+	b.Grow(44 + len(pcm))
+
+	le := binary.LittleEndian
+	b.WriteString("RIFF")
+	binary.Write(&b, le, uint32(36+len(pcm)))
+	b.WriteString("WAVEfmt ")
+	binary.Write(&b, le, uint32(16)) // fmt chunk size
+	binary.Write(&b, le, uint16(1))  // PCM
+	binary.Write(&b, le, uint16(channels))
+	binary.Write(&b, le, uint32(sampleRate))
+	binary.Write(&b, le, uint32(sampleRate*channels*2)) // bytes per second
+	binary.Write(&b, le, uint16(channels*2))            // bytes per frame
+	binary.Write(&b, le, uint16(16))                    // bits per sample
+	b.WriteString("data")
+	binary.Write(&b, le, uint32(len(pcm)))
+	b.Write(pcm)
+	return b.Bytes()
+}
+
+// bookAudio finds and makes the audio for the chunks of one book. The audio lives in
+// the book's cache directory, described by its manifest. Text that isn't from a book,
+// such as a voice sample, is synthesized into memory and not cached.
+type bookAudio struct {
+	synth    Synthesizer
+	params   SynthesisParams
+	dir      string
+	prefix   string
+	manifest *Manifest
+	made     bool // some audio had to be synthesized
+}
+
+// bookAudio gets ready to find and make audio for a book with the given settings
+func (p *player) bookAudio(provider, bookID string, params SynthesisParams) (*bookAudio, error) {
+	b := &bookAudio{synth: p.synth, params: params}
+	if provider == "" || bookID == "" {
+		return b, nil
+	}
+
+	b.dir = p.cache.BookDir(provider, p.name, bookID)
+	b.prefix = bookID
+	if err := os.MkdirAll(b.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory %s: %w", b.dir, err)
+	}
+
+	manifest, err := loadManifest(b.dir)
+	if err != nil {
+		fmt.Printf("%v, the audio will be made again\n", err)
+	}
+	b.manifest = manifest
+	return b, nil
+}
+
+// chunk returns the audio for one chunk of text, synthesizing it unless the manifest
+// vouches for a cached copy. It reports whether the audio had to be made. If started
+// isn't nil and the synthesizer streams, started is given the audio as soon as it
+// begins to arrive.
+func (b *bookAudio) chunk(ctx context.Context, text string, started func(*chunkAudio)) (*chunkAudio, bool, error) {
+	format := b.synth.AudioFormat()
+	if b.dir == "" {
+		data, marks, err := b.synthesize(ctx, text, started)
+		if err != nil {
+			return nil, false, err
+		}
+		return &chunkAudio{format: format, data: data, marks: marks, engine: b.params.Engine}, true, nil
+	}
+
+	file := b.file(text)
+	path := filepath.Join(b.dir, file)
+	if b.manifest.verify(file, b.params, text) {
+		return &chunkAudio{format: format, path: path, marks: loadMarks(path), engine: b.params.Engine}, false, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
+	data, marks, err := b.synthesize(ctx, text, started)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return nil, false, fmt.Errorf("failed to write audio to %s: %w", path, err)
+	}
+	if marks != nil {
+		if err := saveMarks(path, marks); err != nil {
+			fmt.Printf("Could not save word timings for %s: %v\n", file, err)
+		}
+	}
+
+	// Saved after every chunk so an interrupted run keeps what it has made
+	b.manifest.add(file, b.params, text, data, audioDuration(format, data))
+	if err := b.manifest.save(); err != nil {
+		return nil, false, err
+	}
+
+	b.made = true
+	return &chunkAudio{format: format, path: path, marks: marks, engine: b.params.Engine}, true, nil
+}
+
+// file names a chunk's audio after its text and every setting it was made with
+func (b *bookAudio) file(text string) string {
+	return fmt.Sprintf("%s_%s.%s", b.prefix, b.params.chunkKey(text), b.synth.AudioFormat())
+}
+
+// cached reports whether a chunk's audio is in the cache already
+func (b *bookAudio) cached(text string) bool {
+	return b.dir != "" && b.manifest.verify(b.file(text), b.params, text)
+}
+
+// synthesize makes the audio for one chunk, with the header of a WAV written to a pipe put right
+func (b *bookAudio) synthesize(ctx context.Context, text string, started func(*chunkAudio)) ([]byte, []WordMark, error) {
+	if s, ok := b.synth.(pcmStreamer); ok && started != nil {
+		data, err := b.stream(ctx, s, text, started)
+		return data, nil, err
+	}
+
+	data, marks, err := b.synth.Synthesize(ctx, text, b.params)
+	if err != nil {
+		return nil, nil, err
+	}
+	if b.synth.AudioFormat() == "wav" {
+		data = FixWAVSizes(data)
+	}
+	return data, marks, nil
+}
+
+// stream makes a chunk with a synthesizer that streams, handing the audio to started
+// once the first of it arrives, and returns it all as WAV when it is done
+func (b *bookAudio) stream(ctx context.Context, s pcmStreamer, text string, started func(*chunkAudio)) ([]byte, error) {
+	out, sampleRate, err := s.SynthesizeStream(ctx, text, b.params)
+	if err != nil {
+		return nil, err
+	}
+
+	live := newLiveAudio(sampleRate)
+	defer live.finish()
+
+	buf := make([]byte, 16*1024)
+	for {
+		n, err := out.Read(buf)
+		if n > 0 {
+			live.write(buf[:n])
+			if started != nil {
+				started(&chunkAudio{format: b.synth.AudioFormat(), live: live, engine: b.params.Engine})
+				started = nil
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			out.Close()
+			return nil, fmt.Errorf("failed to read the audio from %s: %w", b.params.Engine, err)
+		}
+	}
+	if err := out.Close(); err != nil {
+		return nil, err
+	}
+
+	pcm := live.bytes()
+	if len(pcm) == 0 {
+		return nil, fmt.Errorf("%s produced no audio", b.params.Engine)
+	}
+	return pcmToWAV(pcm, sampleRate, 1), nil
+}
+
+// voiceChain makes the audio for one run with the engine's own synthesizer, moving on to
+// its fallbacks in turn if it fails. Once it has moved on it stays there for the rest of the run.
+type voiceChain struct {
+	players  []*player   // the engine's player, then each fallback it has moved on to
+	next     []*fallback // the fallbacks it hasn't tried yet
+	books    []*bookAudio
+	provider string
+	bookID   string
+}
+
+// voiceChain gets ready to make a run's audio for the book the text belongs to
+func (p *player) voiceChain(provider, bookID string) (*voiceChain, error) {
+	book, err := p.bookAudio(provider, bookID, p.synth.SynthesisParams())
+	if err != nil {
+		return nil, err
+	}
+
+	return &voiceChain{
+		players:  []*player{p},
+		next:     p.fallbacks,
+		books:    []*bookAudio{book},
+		provider: provider,
+		bookID:   bookID,
+	}, nil
+}
+
+// chunk returns the audio for one chunk from the current engine, or from the next engine
+// that can make it. The first chunk a fallback makes records why the engine before failed.
+// started, if not nil, is given audio that can play while it is still being made. Once
+// it has been, a failure isn't handed on: the fallback would play the start again.
+func (c *voiceChain) chunk(ctx context.Context, text string, started func(*chunkAudio)) (*chunkAudio, error) {
+	var failed error
+	for {
+		onStart := started
+		streamed := false
+		if started != nil {
+			onStart = func(live *chunkAudio) {
+				streamed = true
+				live.switched = failed
+				started(live)
+			}
+		}
+
+		audio, err := c.tryCurrent(ctx, text, onStart)
+		if err == nil {
+			audio.switched = failed
+			return audio, nil
+		}
+		if ctx.Err() != nil || streamed {
+			return nil, err
+		}
+
+		failed = fmt.Errorf("%s failed: %w", c.current().synth.SynthesisParams().Engine, err)
+		if !c.moveOn() {
+			return nil, err
+		}
+	}
+}
+
+// current is the engine's player the chain is making audio with
+func (c *voiceChain) current() *player {
+	return c.players[len(c.players)-1]
+}
+
+// moveOn creates the next fallback that can be created and makes it current,
+// reporting false when there are none left
+func (c *voiceChain) moveOn() bool {
+	for len(c.next) > 0 {
+		f := c.next[0]
+		c.next = c.next[1:]
+
+		p, err := f.pipeline()
+		if err != nil || slices.Contains(c.players, p) {
+			continue
+		}
+		c.players = append(c.players, p)
+		c.books = append(c.books, nil)
+		return true
+	}
+	return false
+}
+
+// tryCurrent makes a chunk with the current engine, at its settings as they are now
+func (c *voiceChain) tryCurrent(ctx context.Context, text string, started func(*chunkAudio)) (*chunkAudio, error) {
+	last := len(c.books) - 1
+	p := c.current()
+	if params := p.synth.SynthesisParams(); c.books[last] == nil || c.books[last].params != params {
+		book, err := p.bookAudio(c.provider, c.bookID, params)
+		if err != nil {
+			return nil, err
+		}
+		if old := c.books[last]; old != nil {
+			book.made = old.made
+		}
+		c.books[last] = book
+	}
+
+	audio, _, err := c.books[last].chunk(ctx, text, started)
+	return audio, err
+}
+
+// made reports whether any new audio was cached since it was last asked
+func (c *voiceChain) made() bool {
+	made := false
+	for _, book := range c.books {
+		if book != nil && book.made {
+			book.made = false
+			made = true
+		}
+	}
+	return made
+}
+
+// writeFileAtomic writes data to a temporary file and renames it into place,
+// so an interrupted write never leaves a truncated file in the cache
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// marksPath is where the word timings for a cached chunk are kept
+func marksPath(chunkPath string) string {
+	return strings.TrimSuffix(chunkPath, filepath.Ext(chunkPath)) + ".marks.json"
+}
+
+func saveMarks(chunkPath string, marks []WordMark) error {
+	data, err := json.Marshal(marks)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(marksPath(chunkPath), data)
+}
+
+// loadMarks returns the word timings saved next to a cached chunk, or nil if there are none
+func loadMarks(chunkPath string) []WordMark {
+	data, err := os.ReadFile(marksPath(chunkPath))
+	if err != nil {
+		return nil
+	}
+	var marks []WordMark
+	if json.Unmarshal(data, &marks) != nil {
+		return nil
+	}
+	return marks
+}
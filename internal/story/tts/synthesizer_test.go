@@ -0,0 +1,126 @@
+package tts
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// pipedWAV is 0.1s of 24kHz 16-bit mono WAV with the placeholder sizes a tool
+// writing to stdout leaves in the header
+func pipedWAV() []byte {
+	data := pcmToWAV(make([]byte, 4800), 24000, 1)
+	binary.LittleEndian.PutUint32(data[4:8], 0xFFFFFFFF)
+	binary.LittleEndian.PutUint32(data[40:44], 0xFFFFFFFF)
+	return data
+}
+
+func TestFixWAVSizes(t *testing.T) {
+	data := pipedWAV()
+	fixed := FixWAVSizes(data)
+
+	if got := binary.LittleEndian.Uint32(fixed[4:8]); got != uint32(len(data)-8) {
+		t.Errorf("RIFF size = %d, want %d", got, len(data)-8)
+	}
+	if got := binary.LittleEndian.Uint32(fixed[40:44]); got != 4800 {
+		t.Errorf("data size = %d, want 4800", got)
+	}
+	if binary.LittleEndian.Uint32(data[40:44]) != 0xFFFFFFFF {
+		t.Error("the original data was changed")
+	}
+}
+
+func TestPipedWAVPlaysForItsLength(t *testing.T) {
+	if got := audioDuration("wav", pipedWAV()); got != 100*time.Millisecond {
+		t.Errorf("duration = %v, want 100ms", got)
+	}
+
+	audio := &chunkAudio{format: "wav", data: pipedWAV()}
+	streamer, _, err := audio.open()
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer streamer.Close()
+	if got := streamer.Len(); got != 2400 {
+		t.Errorf("Len = %d samples, want 2400", got)
+	}
+}
+
+func TestPipedWAVIsFixedBeforeCaching(t *testing.T) {
+	cache := NewAudioCache(t.TempDir(), 0, true)
+	p := (&fakeSynth{audio: pipedWAV()}).player(cache)
+
+	book, err := p.bookAudio("test", "book", p.synth.SynthesisParams())
+	if err != nil {
+		t.Fatalf("bookAudio: %v", err)
+	}
+	audio, _, err := book.chunk(context.Background(), "Hello.", nil)
+	if err != nil {
+		t.Fatalf("chunk: %v", err)
+	}
+
+	data, err := audio.read()
+	if err != nil {
+		t.Fatalf("reading the cached audio: %v", err)
+	}
+	if got := binary.LittleEndian.Uint32(data[40:44]); got != 4800 {
+		t.Errorf("cached data size = %d, want 4800", got)
+	}
+}
+
+// playerOnlyEngine is an engine that is nothing but its player
+type playerOnlyEngine struct {
+	*player
+}
+
+func (playerOnlyEngine) SetVoice(voice string) error           { return nil }
+func (playerOnlyEngine) SetSpeed(speed float64) error          { return nil }
+func (playerOnlyEngine) GetAvailableVoices() ([]string, error) { return nil, nil }
+
+func TestStreamedChunkIsNotRemadeByFallback(t *testing.T) {
+	cache := NewAudioCache(t.TempDir(), 0, true)
+	// Streams a little audio for each chunk, then fails
+	failure := errors.New("the server went away")
+	broken := &fakeSynth{
+		engine: "broken",
+		err:    failure,
+		stream: func(text string) (io.ReadCloser, error) {
+			r, w := io.Pipe()
+			go func() {
+				w.Write(make([]byte, 4800))
+				w.CloseWithError(failure)
+			}()
+			return r, nil
+		},
+	}
+	p := broken.player(cache)
+	fallback := &fakeSynth{engine: "fallback"}
+	p.SetFallbacks([]EngineFactory{func() (Engine, error) {
+		return playerOnlyEngine{fallback.player(cache)}, nil
+	}})
+
+	voices, err := p.voiceChain("", "")
+	if err != nil {
+		t.Fatalf("voiceChain: %v", err)
+	}
+
+	// Part of it has played, so playing all of it again on the fallback would repeat the start
+	if _, err := voices.chunk(context.Background(), "Once upon a time.", func(*chunkAudio) {}); err == nil {
+		t.Error("the chunk that failed part way was reported as made")
+	}
+	if made := fallback.count(); made != 0 {
+		t.Errorf("the fallback made the chunk again %d times", made)
+	}
+
+	// Nothing of it has played, so the fallback stands in
+	audio, err := voices.chunk(context.Background(), "Once upon a time.", nil)
+	if err != nil {
+		t.Fatalf("chunk: %v", err)
+	}
+	if made := fallback.count(); audio.engine != "fallback" || made != 1 {
+		t.Errorf("made by %s with the fallback called %d times, want the fallback once", audio.engine, made)
+	}
+}
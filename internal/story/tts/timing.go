@@ -0,0 +1,103 @@
+package tts
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Cue is a sentence with the time it is spoken in the rendered audio
+type Cue struct {
+	Start  time.Duration
+	End    time.Duration
+	Text   string
+	Offset int          // byte offset of Text within the rendered text
+	Words  []WordTiming // only filled in when the engine reported word marks
+}
+
+// WordTiming is a single word within a cue
+type WordTiming struct {
+	Start time.Duration
+	Text  string
+}
+
+// TimingTrack works out when each sentence (and word, where marks exist) is spoken.
+// lengths holds the playback duration of each segment, in order.
+// Without word marks, a segment's time is shared between its sentences by length.
+func TimingTrack(segments []AudioSegment, lengths []time.Duration) []Cue {
+	var cues []Cue
+	var segStart time.Duration
+
+	for i, seg := range segments {
+		if i >= len(lengths) {
+			break
+		}
+		length := lengths[i]
+
+		total := utf8.RuneCountInString(seg.Text)
+		for _, sentence := range splitSentences(seg.Text) {
+			text := strings.TrimSpace(seg.Text[sentence.start:sentence.end])
+			if text == "" || total == 0 {
+				continue
+			}
+			lead := len(seg.Text[sentence.start:sentence.end]) - len(strings.TrimLeftFunc(seg.Text[sentence.start:sentence.end], unicode.IsSpace))
+			textStart := sentence.start + lead
+			textEnd := textStart + len(text)
+
+			cue := Cue{
+				Start:  segStart + proportion(length, seg.Text[:sentence.start], total),
+				End:    segStart + proportion(length, seg.Text[:sentence.end], total),
+				Text:   text,
+				Offset: seg.Offset + textStart,
+			}
+
+			var words []WordMark
+			for _, m := range seg.Marks {
+				if m.Offset >= textStart && m.Offset < textEnd {
+					words = append(words, m)
+				}
+			}
+			if len(words) > 0 {
+				cue.Start = segStart + words[0].Time
+				for _, w := range words {
+					cue.Words = append(cue.Words, WordTiming{
+						Start: segStart + w.Time,
+						Text:  wordAt(seg.Text, w.Offset),
+					})
+				}
+			}
+
+			cues = append(cues, cue)
+		}
+
+		segStart += length
+	}
+
+	// With word marks a sentence runs until the next one starts
+	for i := range cues {
+		if len(cues[i].Words) > 0 && i+1 < len(cues) && cues[i+1].Start > cues[i].Start {
+			cues[i].End = cues[i+1].Start
+		}
+	}
+
+	return cues
+}
+
+// proportion returns the share of length taken by the runes in prefix
+func proportion(length time.Duration, prefix string, total int) time.Duration {
+	return time.Duration(float64(length) * float64(utf8.RuneCountInString(prefix)) / float64(total))
+}
+
+// wordAt returns the word starting at offset in text
+func wordAt(text string, offset int) string {
+	end := offset
+	for end < len(text) {
+		r, size := utf8.DecodeRuneInString(text[end:])
+		if unicode.IsSpace(r) {
+			break
+		}
+		end += size
+	}
+	return text[offset:end]
+}
@@ -0,0 +1,58 @@
+package tts
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTimingTrack(t *testing.T) {
+	ms := time.Millisecond
+	text := []string{"Hi there. Bye now.", "The end."}
+	lengths := []time.Duration{1800 * ms, 800 * ms}
+
+	tests := []struct {
+		name  string
+		marks [][]WordMark
+		want  []Cue
+	}{
+		{
+			// Each segment's time is shared between its sentences by their length
+			name: "without marks",
+			want: []Cue{
+				{Start: 0, End: 1000 * ms, Text: "Hi there.", Offset: 0},
+				{Start: 1000 * ms, End: 1800 * ms, Text: "Bye now.", Offset: 10},
+				{Start: 1800 * ms, End: 2600 * ms, Text: "The end.", Offset: 19},
+			},
+		},
+		{
+			// Sentences start at their first word and run until the next one starts
+			name: "with marks",
+			marks: [][]WordMark{
+				{{Offset: 0, Time: 0}, {Offset: 3, Time: 300 * ms}, {Offset: 10, Time: 1100 * ms}, {Offset: 14, Time: 1300 * ms}},
+				{{Offset: 0, Time: 100 * ms}, {Offset: 4, Time: 400 * ms}},
+			},
+			want: []Cue{
+				{Start: 0, End: 1100 * ms, Text: "Hi there.", Offset: 0,
+					Words: []WordTiming{{Start: 0, Text: "Hi"}, {Start: 300 * ms, Text: "there."}}},
+				{Start: 1100 * ms, End: 1900 * ms, Text: "Bye now.", Offset: 10,
+					Words: []WordTiming{{Start: 1100 * ms, Text: "Bye"}, {Start: 1300 * ms, Text: "now."}}},
+				{Start: 1900 * ms, End: 2600 * ms, Text: "The end.", Offset: 19,
+					Words: []WordTiming{{Start: 1900 * ms, Text: "The"}, {Start: 2200 * ms, Text: "end."}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segments := []AudioSegment{{Text: text[0], Offset: 0}, {Text: text[1], Offset: 19}}
+			for i, marks := range tt.marks {
+				segments[i].Marks = marks
+			}
+
+			got := TimingTrack(segments, lengths)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TimingTrack =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,12 @@
 // internal/story/tts/tts.go
 package tts
 
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
 type Config struct {
 	Type   string
 	Speed  float64
@@ -11,6 +17,9 @@ type Config struct {
 // Engine interface for text-to-speech functionality
 type Engine interface {
 	Speak(text string) error
+	// SpeakContext is Speak bound to ctx: cancelling it (or passing its deadline)
+	// aborts synthesis in flight and stops playback
+	SpeakContext(ctx context.Context, text string) error
 	SetVoice(voice string) error
 	SetSpeed(speed float64) error
 	SetVolume(volume float64) error
@@ -20,7 +29,80 @@ type Engine interface {
 	IsPlaying() bool
 	GetAvailableVoices() ([]string, error)
 
+	// SetBookContext sets the provider and book the next text belongs to, so engines
+	// that cache audio can keep it with the book
 	SetBookContext(provider, bookID string)
+
+	// Capabilities says which controls the engine supports with its current voice
+	Capabilities() Capabilities
+
+	// Subscribe returns a channel of playback events and a func that unsubscribes.
+	// Subscribe before calling Speak so no events are missed.
+	Subscribe() (<-chan Event, func())
+}
+
+// Range is the span of values an engine accepts for a setting such as speed
+type Range struct {
+	Min float64
+	Max float64
+}
+
+// Contains reports whether v is within the range
+func (r Range) Contains(v float64) bool {
+	return v >= r.Min && v <= r.Max
+}
+
+// check returns an error naming the setting if v is out of range
+func (r Range) check(name string, v float64) error {
+	if !r.Contains(v) {
+		return fmt.Errorf("%s must be between %g and %g", name, r.Min, r.Max)
+	}
+	return nil
+}
+
+// Capabilities describes what an engine can do, so only the controls that work are offered
+type Capabilities struct {
+	Pause       bool
+	Seek        bool // can move around in the text being spoken without starting again
+	SSML        bool // the voice understands SSML
+	Caching     bool // synthesized audio is kept, so books replay without synthesizing again
+	WordTimings bool // reports when each word is spoken, for read-along
+	Streaming   bool // starts playing before the whole text has been synthesized
+	Speed       Range
+	Volume      Range
+}
+
+// CanSetSpeed reports whether the engine reads at more than one speed
+func (c Capabilities) CanSetSpeed() bool {
+	return c.Speed.Max > c.Speed.Min
+}
+
+// CanSetVolume reports whether the engine plays at more than one volume
+func (c Capabilities) CanSetVolume() bool {
+	return c.Volume.Max > c.Volume.Min
+}
+
+// Supported lists the features the engine has, for display
+func (c Capabilities) Supported() []string {
+	var names []string
+	for _, feature := range []struct {
+		name string
+		ok   bool
+	}{
+		{"pause", c.Pause},
+		{"seek", c.Seek},
+		{"speed", c.CanSetSpeed()},
+		{"volume", c.CanSetVolume()},
+		{"SSML", c.SSML},
+		{"caching", c.Caching},
+		{"word timings", c.WordTimings},
+		{"streaming", c.Streaming},
+	} {
+		if feature.ok {
+			names = append(names, feature.name)
+		}
+	}
+	return names
 }
 
 // CacheableEngine extends Engine with cache management capabilities
@@ -30,6 +112,46 @@ type CacheableEngine interface {
 	ClearCache() error
 }
 
+// PrefetchEngine can synthesize a book's audio into the cache ahead of time
+type PrefetchEngine interface {
+	Engine
+	Prefetch(ctx context.Context, provider, bookID, text string, progress func(done, total int)) error
+}
+
+// HealthEngine can check that it works, e.g. that its credentials are accepted or its
+// server answers, before it is relied on
+type HealthEngine interface {
+	Engine
+	Probe(ctx context.Context) error
+}
+
+// EngineFactory creates an engine when it is first needed
+type EngineFactory func() (Engine, error)
+
+// FallbackEngine can hand synthesis to other engines when its own fails part way through a story
+type FallbackEngine interface {
+	Engine
+	// SetFallbacks sets the engines to carry on with, in order. Each is only created once
+	// the engine before it fails; engines that can't synthesize for another engine's
+	// playback are skipped.
+	SetFallbacks(fallbacks []EngineFactory)
+}
+
+// CacheManager looks after the cached audio of every engine, book by book
+type CacheManager interface {
+	Root() string
+	Enabled() bool
+	Limit() int64
+	Books() ([]CachedBook, error)
+	Pin(provider, bookID string, pinned bool) error
+	PinnedBooks() ([]CachedBook, error)
+	Remove(provider, bookID string) (CachedBook, bool, error)
+	RemoveProvider(provider string) ([]CachedBook, error)
+	Prune(age time.Duration) ([]CachedBook, error)
+	Verify(fix bool) ([]CacheProblem, error)
+	Clear() error
+}
+
 // VoiceInfo provides detailed information about available voices
 type VoiceInfo struct {
 	Name         string `json:"name"`
@@ -45,3 +167,60 @@ type EnhancedEngine interface {
 	GetVoiceInfo() ([]VoiceInfo, error)
 	IsPaused() bool
 }
+
+// ProgressEngine reports how far through the current text playback has got
+type ProgressEngine interface {
+	Engine
+	// Offset returns the byte offset in the text passed to Speak where playback
+	// should pick up again: the start of the chunk playing now, or of the next
+	// chunk if playback stopped at a boundary
+	Offset() int
+}
+
+// FollowEngine reports which part of the text is being spoken, for read-along display
+type FollowEngine interface {
+	Engine
+	// Spoken returns the byte range of the text passed to Speak that is being spoken now:
+	// the current word where the engine knows word timings, otherwise the current chunk
+	Spoken() (start, end int, ok bool)
+}
+
+// SeekableEngine can move playback around in the text being spoken
+type SeekableEngine interface {
+	Engine
+	Position() Position
+	// Seek carries on playing from offset, a byte offset into the text passed to Speak.
+	// It keeps the paused state, so seeking while paused stays paused.
+	Seek(offset int) error
+}
+
+// FadingEngine can wind playback down gently, e.g. for a sleep timer
+type FadingEngine interface {
+	Engine
+	// FadeVolume ramps the volume to level (a fraction of the configured volume) over the given duration
+	FadeVolume(level float64, over time.Duration)
+	// StopAtBoundary lets the current chunk finish and then stops playback
+	StopAtBoundary()
+}
+
+// AudioSegment is the synthesized audio for one chunk of text
+type AudioSegment struct {
+	Format string // "mp3" or "wav"
+	Data   []byte
+	Text   string
+	Offset int // byte offset of Text within the rendered text
+	Marks  []WordMark
+}
+
+// WordMark records when a word starts in a segment's audio, for engines that report it
+type WordMark struct {
+	Offset int           `json:"offset"` // byte offset of the word within the segment text
+	Time   time.Duration `json:"time"`
+}
+
+// RenderingEngine can synthesize text to audio data instead of playing it, e.g. for export
+type RenderingEngine interface {
+	Engine
+	// Render returns the audio for each chunk of text. Cancelling ctx stops synthesis.
+	Render(ctx context.Context, text string) ([]AudioSegment, error)
+}
@@ -0,0 +1,146 @@
+package tts
+
+import (
+	"sort"
+	"strings"
+
+	texttospeechpb "google.golang.org/genproto/googleapis/cloud/texttospeech/v1"
+)
+
+// Voice genders as reported in VoiceInfo
+const (
+	GenderFemale  = "female"
+	GenderMale    = "male"
+	GenderNeutral = "neutral"
+)
+
+// googleVoiceFamilies describes the kinds of Google voice, keyed by the part of the name after the language
+var googleVoiceFamilies = map[string]string{
+	"Standard":  "Standard voice",
+	"Wavenet":   "WaveNet voice",
+	"Neural2":   "Neural2 voice",
+	"News":      "News reader voice",
+	"Studio":    "Studio voice for narration",
+	"Polyglot":  "Polyglot voice",
+	"Journey":   "Journey voice, warm and expressive",
+	"Casual":    "Casual conversational voice",
+	"Chirp-HD":  "Chirp HD voice",
+	"Chirp3-HD": "Chirp 3 HD voice, the most natural",
+}
+
+// googleVoiceInfo turns a voice from ListVoices into VoiceInfo
+func googleVoiceInfo(v *texttospeechpb.Voice) VoiceInfo {
+	info := VoiceInfo{Name: v.Name, Gender: googleGender(v.SsmlGender)}
+	if len(v.LanguageCodes) > 0 {
+		info.LanguageCode = v.LanguageCodes[0]
+	}
+
+	family := googleVoiceFamily(v.Name, info.LanguageCode)
+	info.Natural = family != "Standard"
+	info.Description = googleVoiceFamilies[family]
+	if len(v.LanguageCodes) > 1 {
+		info.Description = strings.TrimPrefix(info.Description+"; also speaks "+strings.Join(v.LanguageCodes[1:], ", "), "; ")
+	}
+	return info
+}
+
+// googleVoiceFamily returns the kind of voice from a name like "en-GB-Chirp3-HD-Umbriel"
+func googleVoiceFamily(name, languageCode string) string {
+	rest := strings.TrimPrefix(name, languageCode+"-")
+	if i := strings.LastIndex(rest, "-"); i >= 0 {
+		return rest[:i]
+	}
+	return rest
+}
+
+func googleGender(g texttospeechpb.SsmlVoiceGender) string {
+	switch g {
+	case texttospeechpb.SsmlVoiceGender_FEMALE:
+		return GenderFemale
+	case texttospeechpb.SsmlVoiceGender_MALE:
+		return GenderMale
+	case texttospeechpb.SsmlVoiceGender_NEUTRAL:
+		return GenderNeutral
+	default:
+		return ""
+	}
+}
+
+// parseESpeakVoiceInfo reads the table printed by 'espeak --voices':
+// Pty Language Age/Gender VoiceName File Other Languages
+func parseESpeakVoiceInfo(output string) []VoiceInfo {
+	var voices []VoiceInfo
+
+	for i, line := range strings.Split(output, "\n") {
+		// Skip header line
+		if i == 0 || strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+
+		info := VoiceInfo{
+			Name:         fields[3],
+			LanguageCode: languageTag(fields[1]),
+		}
+		if _, gender, ok := strings.Cut(fields[2], "/"); ok {
+			info.Gender = genderName(gender)
+		}
+		if len(fields) >= 5 {
+			info.Description = "eSpeak voice " + fields[4]
+		}
+		if len(fields) >= 6 {
+			info.Description += ", also " + strings.Join(fields[5:], " ")
+		}
+
+		voices = append(voices, info)
+	}
+
+	return voices
+}
+
+// genderName turns the gender spellings engines use ("F", "Female", "NotSet"...) into the Gender constants
+func genderName(gender string) string {
+	switch strings.ToLower(gender) {
+	case "f", "female":
+		return GenderFemale
+	case "m", "male":
+		return GenderMale
+	case "n", "neutral":
+		return GenderNeutral
+	default:
+		return ""
+	}
+}
+
+// languageTag writes language codes like "en-gb" or "en_GB" as "en-GB"
+func languageTag(code string) string {
+	parts := strings.Split(strings.ReplaceAll(code, "_", "-"), "-")
+	parts[0] = strings.ToLower(parts[0])
+	if len(parts) > 1 && len(parts[1]) == 2 {
+		parts[1] = strings.ToUpper(parts[1])
+	}
+	return strings.Join(parts, "-")
+}
+
+// voiceNames lists the names of voices
+func voiceNames(voices []VoiceInfo) []string {
+	names := make([]string, len(voices))
+	for i, v := range voices {
+		names[i] = v.Name
+	}
+	return names
+}
+
+// sortVoices orders voices by language, then name
+func sortVoices(voices []VoiceInfo) {
+	sort.Slice(voices, func(i, j int) bool {
+		if voices[i].LanguageCode != voices[j].LanguageCode {
+			return voices[i].LanguageCode < voices[j].LanguageCode
+		}
+		return voices[i].Name < voices[j].Name
+	})
+}